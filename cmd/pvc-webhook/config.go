@@ -0,0 +1,136 @@
+// Configuration layering. Every knob resolves through the same precedence:
+// command-line flags override environment variables, which override the
+// optional config file named by PVC_WEBHOOK_CONFIG — a flat JSON object
+// mapping setting names to string values, e.g. {"DEFAULT_SIZE": "5Gi",
+// "METRICS_ADDR": ":9090"}. Flags take their environment/file value as the
+// default (--metrics-addr reads METRICS_ADDR), so both roles' knobs are
+// reachable from all three layers. The secret store mounted via --secrets-dir
+// additionally sits above the environment for the keys it covers.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// configFileEnv names the environment variable pointing at the config file.
+const configFileEnv = "PVC_WEBHOOK_CONFIG"
+
+var (
+	fileSettings     map[string]string
+	fileSettingsOnce sync.Once
+)
+
+// fileSetting returns a value from the config file, or "" when no file is
+// configured or the key is absent. The file is loaded once per process.
+func fileSetting(key string) string {
+	fileSettingsOnce.Do(func() {
+		path := os.Getenv(configFileEnv)
+		if path == "" {
+			return
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			klog.Fatalf("reading config file %s: %v", path, err)
+		}
+		if err := json.Unmarshal(raw, &fileSettings); err != nil {
+			klog.Fatalf("parsing config file %s: %v", path, err)
+		}
+	})
+	return fileSettings[key]
+}
+
+// applyEnvDefaults seeds each flag's default from the environment and config
+// file before parsing, mapping the flag name onto a setting key
+// (--metrics-addr reads METRICS_ADDR). Values given on the command line are
+// parsed afterwards and win.
+func applyEnvDefaults(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		key := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		v := os.Getenv(key)
+		if v == "" {
+			v = fileSetting(key)
+		}
+		if v == "" {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			klog.Warningf("ignoring invalid %s value %q for --%s: %v", key, v, f.Name, err)
+		}
+	})
+}
+
+// settingKeys are the configuration keys read via setting() rather than
+// flags, enumerated so --print-config can show them resolved.
+var settingKeys = []string{
+	"BACKUP_ANNOTATIONS",
+	"BACKUP_LABELS",
+	"CEL_RULES",
+	"CLAIM_LABELS",
+	"CLASS_DEFAULT_SIZES",
+	"DEFAULT_ACCESS_MODES",
+	"DEFAULT_SIZE",
+	"DEFAULT_SNAPSHOT_CLASS",
+	"DEFAULT_STORAGE_CLASS",
+	"ENCRYPTED_STORAGE_CLASSES",
+	"EXCLUDED_IMAGES",
+	"EXCLUDED_NAMESPACES",
+	"EXCLUDED_VOLUME_NAMES",
+	"KUBECONFIG",
+	"MIN_VOLUME_SIZE",
+	"NAMESPACE_TIER_CLASSES",
+	"NAMESPACE_TIER_LABEL",
+	"POLICY_FAIL_CLOSED",
+	"POLICY_TIMEOUT",
+	"POLICY_URL",
+	"PREVIEW_AUTH_TOKEN",
+	"PROFILES",
+	"SCHEDULING_GATE",
+	"SENSITIVE_NAMESPACE_LABEL",
+	"SHADOW_MODE",
+	"SIZE_CEILING",
+	"SIZE_FLOOR",
+	"STRICT_VALIDATION",
+	"SYNC_CREATE",
+	"VOLUME_NAME_PATTERNS",
+}
+
+// printConfig dumps the fully resolved configuration as indented JSON: every
+// flag after layering, plus the non-flag settings that are set. Sensitive
+// values are redacted, so the output is safe to paste into a bug report.
+func printConfig(fs *flag.FlagSet) {
+	out := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		out["--"+f.Name] = redactSetting(f.Name, f.Value.String())
+	})
+	for _, key := range settingKeys {
+		if v := setting(key); v != "" {
+			out[key] = redactSetting(key, v)
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		fmt.Fprintf(os.Stderr, "encoding configuration: %v\n", err)
+	}
+}
+
+// redactSetting hides values of keys that look like credentials.
+func redactSetting(key, value string) string {
+	if value == "" {
+		return value
+	}
+	upper := strings.ToUpper(key)
+	for _, marker := range []string{"TOKEN", "SECRET", "PASSWORD"} {
+		if strings.Contains(upper, marker) {
+			return "(redacted)"
+		}
+	}
+	return value
+}
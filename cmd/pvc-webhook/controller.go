@@ -0,0 +1,198 @@
+// The controller subcommand runs the controller that creates the
+// PersistentVolumeClaims referenced by pods mutated by the webhook.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/controller"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// controllerOptions carries the controller subcommand's flag values.
+type controllerOptions struct {
+	kubeconfig           string
+	sweepInterval        time.Duration
+	gracePeriod          time.Duration
+	orphanAction         string
+	createQPS            float64
+	createBurst          int
+	provisioningDeadline time.Duration
+	timeoutAction        string
+	derivedClasses       bool
+	enableVAC            bool
+	preferRWOP           bool
+	metricsAddr          string
+	autoscaleInterval    time.Duration
+	autoscaleThreshold   int
+	autoscaleCap         string
+	usageInterval        time.Duration
+	pendingThreshold     time.Duration
+	crossNSDataSources   bool
+	secretsDir           string
+	adoptionPolicy       string
+	recreateDeleted      bool
+	stuckThreshold       time.Duration
+	forceRelease         bool
+	recordTTL            time.Duration
+}
+
+// register binds the controller flags to a flag set, so the controller and
+// all subcommands share one definition.
+func (o *controllerOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "path to a kubeconfig; empty means in-cluster")
+	fs.DurationVar(&o.sweepInterval, "orphan-sweep-interval", time.Hour, "how often to scan for orphaned claims; 0 disables the sweeper")
+	fs.DurationVar(&o.gracePeriod, "orphan-grace-period", 30*time.Minute, "how long a claim must stay orphaned before it is acted on")
+	fs.StringVar(&o.orphanAction, "orphan-action", string(controller.OrphanDelete), "what to do with orphaned claims: delete or flag")
+	fs.Float64Var(&o.createQPS, "pvc-creates-per-second", 0, "per-namespace rate limit for claim creation; 0 disables limiting")
+	fs.IntVar(&o.createBurst, "pvc-create-burst", 5, "per-namespace burst allowance for claim creation")
+	fs.DurationVar(&o.provisioningDeadline, "provisioning-deadline", 0, "how long a claim may stay Pending before the timeout action runs; 0 disables")
+	fs.StringVar(&o.timeoutAction, "provisioning-timeout-action", string(controller.TimeoutWarn), "action for claims missing the deadline: warn, annotate, or delete")
+	fs.BoolVar(&o.derivedClasses, "derived-storage-classes", false, "create derived StorageClasses for per-claim storage class parameters")
+	fs.BoolVar(&o.enableVAC, "enable-volume-attributes-classes", false, "honor volumeAttributesClass annotations on clusters serving the API")
+	fs.BoolVar(&o.preferRWOP, "prefer-read-write-once-pod", false, "default converted volumes to ReadWriteOncePod on supporting clusters")
+	fs.StringVar(&o.metricsAddr, "metrics-addr", ":9090", "address serving Prometheus metrics; empty disables the listener")
+	fs.DurationVar(&o.autoscaleInterval, "autoscale-interval", 0, "how often to sample kubelet volume stats and expand full claims; 0 disables autoscaling")
+	fs.IntVar(&o.autoscaleThreshold, "autoscale-threshold-percent", 80, "usage percentage above which a managed claim is expanded")
+	fs.StringVar(&o.autoscaleCap, "autoscale-namespace-cap", "", "total managed storage allowed per namespace after expansion, e.g. 500Gi; empty means no cap")
+	fs.DurationVar(&o.usageInterval, "usage-metrics-interval", time.Minute, "how often to republish kubelet volume stats for managed claims as metrics; 0 disables")
+	fs.DurationVar(&o.pendingThreshold, "pending-warning-threshold", 5*time.Minute, "how long a claim may stay Pending before warning events are posted on its pods; 0 disables")
+	fs.BoolVar(&o.crossNSDataSources, "enable-cross-namespace-data-sources", false, "honor data-source-namespace annotations, subject to ReferenceGrant checks")
+	fs.StringVar(&o.adoptionPolicy, "adoption-policy", string(controller.AdoptionAdopt), "what to do with pre-existing claims not created by pvc-webhook: adopt, reuse-readonly, or error")
+	fs.BoolVar(&o.recreateDeleted, "recreate-deleted-claims", true, "recreate managed claims deleted out-of-band while a pod still references them")
+	fs.DurationVar(&o.stuckThreshold, "stuck-terminating-threshold", 10*time.Minute, "how long a managed claim may stay Terminating before it is reported as stuck; 0 disables the check")
+	fs.BoolVar(&o.forceRelease, "force-release-stuck-claims", false, "remove the pvc-protection finalizer from stuck claims no pod references anymore")
+	fs.DurationVar(&o.recordTTL, "conversion-record-ttl", 30*24*time.Hour, "how long ConversionRecord audit objects are kept before the sweeper deletes them; 0 keeps them forever")
+	if fs.Lookup("secrets-dir") == nil {
+		fs.StringVar(&o.secretsDir, "secrets-dir", "", "directory of a mounted Secret whose files override same-named environment settings")
+	}
+}
+
+// run starts the controller and blocks until its context ends.
+func (o *controllerOptions) run(ctx context.Context) {
+	initSecretStore(o.secretsDir)
+	cfg, err := buildConfig(o.kubeconfig)
+	if err != nil {
+		klog.Fatalf("building client config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("building clientset: %v", err)
+	}
+
+	ctrlCfg := controller.Config{
+		DefaultSize:                     envOr("DEFAULT_SIZE", "2Gi"),
+		DefaultStorageClass:             setting("DEFAULT_STORAGE_CLASS"),
+		ClassDefaultSizes:               parseKeyValues(setting("CLASS_DEFAULT_SIZES")),
+		OrphanSweepInterval:             o.sweepInterval,
+		OrphanGracePeriod:               o.gracePeriod,
+		OrphanAction:                    controller.OrphanAction(o.orphanAction),
+		ClaimCreatesPerSecond:           o.createQPS,
+		ClaimCreateBurst:                o.createBurst,
+		ProvisioningDeadline:            o.provisioningDeadline,
+		ProvisioningTimeoutAction:       controller.TimeoutAction(o.timeoutAction),
+		DefaultSnapshotClass:            setting("DEFAULT_SNAPSHOT_CLASS"),
+		BackupLabels:                    parseKeyValues(setting("BACKUP_LABELS")),
+		BackupAnnotations:               parseKeyValues(setting("BACKUP_ANNOTATIONS")),
+		DerivedStorageClasses:           o.derivedClasses,
+		EnableVolumeAttributesClasses:   o.enableVAC,
+		PreferReadWriteOncePod:          o.preferRWOP,
+		AutoscaleInterval:               o.autoscaleInterval,
+		AutoscaleThresholdPercent:       o.autoscaleThreshold,
+		AutoscaleNamespaceCap:           o.autoscaleCap,
+		UsageMetricsInterval:            o.usageInterval,
+		Profiles:                        parseProfiles(setting("PROFILES")),
+		PendingWarningThreshold:         o.pendingThreshold,
+		EnableCrossNamespaceDataSources: o.crossNSDataSources,
+		AdoptionPolicy:                  controller.AdoptionPolicy(o.adoptionPolicy),
+		RecreateDeletedClaims:           o.recreateDeleted,
+		StuckTerminatingThreshold:       o.stuckThreshold,
+		ForceReleaseStuckClaims:         o.forceRelease,
+		ConversionRecordTTL:             o.recordTTL,
+	}
+	if err := validateControllerConfig(ctrlCfg); err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
+	if err := verifyStorageClass(ctx, client, ctrlCfg.DefaultStorageClass); err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
+
+	c := controller.New(client, ctrlCfg)
+	if dyn, err := dynamic.NewForConfig(cfg); err != nil {
+		klog.Warningf("building dynamic client, CRD features disabled: %v", err)
+	} else {
+		c.SetDynamicClient(dyn)
+	}
+
+	if o.metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			mux.HandleFunc("/version", serveVersion)
+			mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+				if err := c.WatchHealthy(); err != nil {
+					http.Error(w, err.Error(), http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			if err := http.ListenAndServe(o.metricsAddr, mux); err != nil {
+				klog.Errorf("metrics listener failed: %v", err)
+			}
+		}()
+	}
+
+	klog.Info("starting pvc-webhook controller")
+	c.Run(ctx)
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
+
+// parseProfiles decodes the PROFILES environment variable, a JSON map of
+// profile name to parameters, e.g.
+// {"gold":{"storageClass":"premium","size":"100Gi","reclaimPolicy":"Retain"}}.
+func parseProfiles(value string) map[string]controller.Profile {
+	if value == "" {
+		return nil
+	}
+	profiles := map[string]controller.Profile{}
+	if err := json.Unmarshal([]byte(value), &profiles); err != nil {
+		klog.Warningf("ignoring malformed PROFILES: %v", err)
+		return nil
+	}
+	return profiles
+}
+
+// parseKeyValues parses "k1=v1,k2=v2" into a map; empty input yields nil.
+func parseKeyValues(value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+	out := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			klog.Warningf("ignoring malformed key=value entry %q", pair)
+			continue
+		}
+		out[parts[0]] = parts[1]
+	}
+	return out
+}
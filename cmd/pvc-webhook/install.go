@@ -0,0 +1,431 @@
+// The install subcommand provisions the cluster resources the webhook needs:
+// Service, RBAC, serving certificate Secret, and the
+// MutatingWebhookConfiguration. It is a self-contained installer for clusters
+// where Helm is not available.
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// installOptions carries the install subcommand's flag values.
+type installOptions struct {
+	kubeconfig        string
+	namespace         string
+	serviceName       string
+	secretName        string
+	webhookName       string
+	targetPort        int
+	failurePolicy     string
+	namespaceSelector string
+	objectSelector    string
+	certValidity      time.Duration
+}
+
+// register binds the install flags to a flag set.
+func (o *installOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "path to a kubeconfig; empty means in-cluster")
+	fs.StringVar(&o.namespace, "namespace", "pvc-webhook", "namespace the webhook runs in")
+	fs.StringVar(&o.serviceName, "service-name", "pvc-webhook", "name of the Service fronting the webhook")
+	fs.StringVar(&o.secretName, "secret-name", "pvc-webhook-certs", "name of the Secret holding the serving certificate")
+	fs.StringVar(&o.webhookName, "webhook-name", "pvc-webhook", "name of the MutatingWebhookConfiguration")
+	fs.IntVar(&o.targetPort, "target-port", 8443, "container port the Service forwards admission traffic to")
+	fs.StringVar(&o.failurePolicy, "failure-policy", "Ignore", "webhook failure policy: Ignore or Fail")
+	fs.StringVar(&o.namespaceSelector, "namespace-selector", "", "label selector as k=v pairs limiting which namespaces are mutated; empty selects all")
+	fs.StringVar(&o.objectSelector, "object-selector", "", "label selector as k=v pairs limiting which pods are mutated; empty selects all")
+	fs.DurationVar(&o.certValidity, "cert-validity", 5*365*24*time.Hour, "validity of the generated serving certificate")
+}
+
+// run installs or updates the cluster resources and reports what changed.
+func (o *installOptions) run(ctx context.Context) {
+	cfg, err := buildConfig(o.kubeconfig)
+	if err != nil {
+		klog.Fatalf("building client config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("building clientset: %v", err)
+	}
+
+	caBundle, err := o.ensureTLSSecret(ctx, client)
+	if err != nil {
+		klog.Fatalf("installing serving certificate: %v", err)
+	}
+	if err := o.ensureService(ctx, client); err != nil {
+		klog.Fatalf("installing service: %v", err)
+	}
+	if err := o.ensureRBAC(ctx, client); err != nil {
+		klog.Fatalf("installing RBAC: %v", err)
+	}
+	if err := o.ensureWebhookConfiguration(ctx, client, caBundle); err != nil {
+		klog.Fatalf("installing webhook configuration: %v", err)
+	}
+}
+
+// report prints a one-line outcome for a resource, kubectl-style.
+func report(kind, name, action string) {
+	fmt.Printf("%s/%s %s\n", kind, name, action)
+}
+
+// ensureTLSSecret creates the serving certificate Secret if it does not
+// exist, generating a self-signed CA and a leaf certificate for the Service
+// DNS names. An existing secret is left alone so rollouts do not invalidate
+// the certificate the running pods serve. It returns the CA bundle for the
+// webhook configuration.
+func (o *installOptions) ensureTLSSecret(ctx context.Context, client kubernetes.Interface) ([]byte, error) {
+	existing, err := client.CoreV1().Secrets(o.namespace).Get(ctx, o.secretName, metav1.GetOptions{})
+	if err == nil {
+		if ca, ok := existing.Data["ca.crt"]; ok {
+			report("secret", o.secretName, "unchanged")
+			return ca, nil
+		}
+		return nil, fmt.Errorf("secret %s/%s exists but has no ca.crt key", o.namespace, o.secretName)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	dnsNames := []string{
+		o.serviceName,
+		o.serviceName + "." + o.namespace,
+		o.serviceName + "." + o.namespace + ".svc",
+		o.serviceName + "." + o.namespace + ".svc.cluster.local",
+	}
+	caPEM, certPEM, keyPEM, err := generateServingCert(dnsNames, o.certValidity)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.secretName,
+			Namespace: o.namespace,
+			Labels:    map[string]string{"app": "pvc-webhook"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			"tls.crt": certPEM,
+			"tls.key": keyPEM,
+			"ca.crt":  caPEM,
+		},
+	}
+	if _, err := client.CoreV1().Secrets(o.namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return nil, err
+	}
+	report("secret", o.secretName, "created")
+	return caPEM, nil
+}
+
+// generateServingCert returns a self-signed CA and a leaf certificate for the
+// given DNS names, all PEM encoded.
+func generateServingCert(dnsNames []string, validity time.Duration) (caPEM, certPEM, keyPEM []byte, err error) {
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pvc-webhook-ca"},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(validity),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(cryptorand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), cryptorand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(cryptorand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(leafKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return caPEM, certPEM, keyPEM, nil
+}
+
+// ensureService creates or updates the Service fronting the webhook.
+func (o *installOptions) ensureService(ctx context.Context, client kubernetes.Interface) error {
+	desired := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.serviceName,
+			Namespace: o.namespace,
+			Labels:    map[string]string{"app": "pvc-webhook"},
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "pvc-webhook"},
+			Ports: []corev1.ServicePort{{
+				Name:       "https",
+				Port:       443,
+				TargetPort: intstr.FromInt(o.targetPort),
+			}},
+		},
+	}
+	existing, err := client.CoreV1().Services(o.namespace).Get(ctx, o.serviceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.CoreV1().Services(o.namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		report("service", o.serviceName, "created")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepDerivative(desired.Spec, existing.Spec) {
+		report("service", o.serviceName, "unchanged")
+		return nil
+	}
+	existing.Spec.Selector = desired.Spec.Selector
+	existing.Spec.Ports = desired.Spec.Ports
+	if _, err := client.CoreV1().Services(o.namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	report("service", o.serviceName, "updated")
+	return nil
+}
+
+// installRBACRules are the cluster-wide permissions the webhook and the
+// controller need.
+var installRBACRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"get", "list", "watch", "update", "patch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"persistentvolumeclaims"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"events"},
+		Verbs:     []string{"create", "patch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"batch"},
+		Resources: []string{"jobs"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"storage.k8s.io"},
+		Resources: []string{"storageclasses", "volumeattributesclasses"},
+		Verbs:     []string{"get", "list", "watch", "create"},
+	},
+	{
+		APIGroups: []string{"pvc-webhook.io"},
+		Resources: []string{"storagebudgets", "storagebudgets/status", "managedvolumeclaims", "managedvolumeclaims/status", "pvctemplates", "storagelimitranges", "conversionrecords"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	},
+}
+
+// ensureRBAC creates or updates the ServiceAccount, ClusterRole, and
+// ClusterRoleBinding.
+func (o *installOptions) ensureRBAC(ctx context.Context, client kubernetes.Interface) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc-webhook",
+			Namespace: o.namespace,
+			Labels:    map[string]string{"app": "pvc-webhook"},
+		},
+	}
+	if _, err := client.CoreV1().ServiceAccounts(o.namespace).Get(ctx, sa.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := client.CoreV1().ServiceAccounts(o.namespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		report("serviceaccount", sa.Name, "created")
+	} else if err != nil {
+		return err
+	} else {
+		report("serviceaccount", sa.Name, "unchanged")
+	}
+
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pvc-webhook",
+			Labels: map[string]string{"app": "pvc-webhook"},
+		},
+		Rules: installRBACRules,
+	}
+	existingRole, err := client.RbacV1().ClusterRoles().Get(ctx, role.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := client.RbacV1().ClusterRoles().Create(ctx, role, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		report("clusterrole", role.Name, "created")
+	case err != nil:
+		return err
+	case equality.Semantic.DeepEqual(existingRole.Rules, role.Rules):
+		report("clusterrole", role.Name, "unchanged")
+	default:
+		existingRole.Rules = role.Rules
+		if _, err := client.RbacV1().ClusterRoles().Update(ctx, existingRole, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		report("clusterrole", role.Name, "updated")
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "pvc-webhook",
+			Labels: map[string]string{"app": "pvc-webhook"},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     role.Name,
+		},
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      sa.Name,
+			Namespace: o.namespace,
+		}},
+	}
+	existingBinding, err := client.RbacV1().ClusterRoleBindings().Get(ctx, binding.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := client.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		report("clusterrolebinding", binding.Name, "created")
+	case err != nil:
+		return err
+	case equality.Semantic.DeepEqual(existingBinding.Subjects, binding.Subjects) && existingBinding.RoleRef == binding.RoleRef:
+		report("clusterrolebinding", binding.Name, "unchanged")
+	default:
+		// RoleRef is immutable, so a drifted binding is recreated.
+		if err := client.RbacV1().ClusterRoleBindings().Delete(ctx, binding.Name, metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		if _, err := client.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		report("clusterrolebinding", binding.Name, "updated")
+	}
+	return nil
+}
+
+// ensureWebhookConfiguration creates or updates the
+// MutatingWebhookConfiguration pointing at the Service.
+func (o *installOptions) ensureWebhookConfiguration(ctx context.Context, client kubernetes.Interface, caBundle []byte) error {
+	failurePolicy := admissionregistrationv1.FailurePolicyType(o.failurePolicy)
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	path := "/mutate"
+	desired := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   o.webhookName,
+			Labels: map[string]string{"app": "pvc-webhook"},
+		},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name:                    "pods.pvc-webhook.io",
+			AdmissionReviewVersions: []string{"v1", "v1beta1"},
+			SideEffects:             &sideEffects,
+			FailurePolicy:           &failurePolicy,
+			NamespaceSelector:       parseSelector(o.namespaceSelector),
+			ObjectSelector:          parseSelector(o.objectSelector),
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: o.namespace,
+					Name:      o.serviceName,
+					Path:      &path,
+				},
+				CABundle: caBundle,
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{
+					admissionregistrationv1.Create,
+					admissionregistrationv1.Update,
+				},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				},
+			}},
+		}},
+	}
+	existing, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(ctx, o.webhookName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		report("mutatingwebhookconfiguration", o.webhookName, "created")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if equality.Semantic.DeepDerivative(desired.Webhooks, existing.Webhooks) {
+		report("mutatingwebhookconfiguration", o.webhookName, "unchanged")
+		return nil
+	}
+	existing.Webhooks = desired.Webhooks
+	if _, err := client.AdmissionregistrationV1().MutatingWebhookConfigurations().Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	report("mutatingwebhookconfiguration", o.webhookName, "updated")
+	return nil
+}
+
+// parseSelector turns "k1=v1,k2=v2" into a matchLabels selector; empty input
+// yields nil, which selects everything.
+func parseSelector(value string) *metav1.LabelSelector {
+	labels := parseKeyValues(value)
+	if len(labels) == 0 {
+		return nil
+	}
+	return &metav1.LabelSelector{MatchLabels: labels}
+}
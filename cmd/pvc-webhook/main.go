@@ -0,0 +1,125 @@
+// Command pvc-webhook is the single binary for the project. Subcommands
+// select which role the process runs: the mutating admission webhook, the
+// claim controller, or both at once, so one image serves every deployment
+// shape.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/version"
+)
+
+func main() {
+	metrics.BuildInfo.WithLabelValues(version.Version, version.GitSHA, version.BuildDate).Set(1)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+
+	switch cmd {
+	case "webhook":
+		opts := &webhookOptions{}
+		fs := newFlagSet(cmd)
+		opts.register(fs)
+		parse(fs, args)
+		opts.run()
+	case "controller":
+		opts := &controllerOptions{}
+		fs := newFlagSet(cmd)
+		opts.register(fs)
+		parse(fs, args)
+		opts.run(context.Background())
+	case "all":
+		webhookOpts := &webhookOptions{}
+		controllerOpts := &controllerOptions{}
+		fs := newFlagSet(cmd)
+		controllerOpts.register(fs)
+		webhookOpts.register(fs)
+		parse(fs, args)
+		// The controller runs alongside the webhook; the webhook owns the
+		// process lifecycle and its shutdown takes the controller with it.
+		go controllerOpts.run(context.Background())
+		webhookOpts.run()
+	case "install":
+		opts := &installOptions{}
+		fs := newFlagSet(cmd)
+		opts.register(fs)
+		parse(fs, args)
+		opts.run(context.Background())
+	case "uninstall":
+		opts := &uninstallOptions{}
+		fs := newFlagSet(cmd)
+		opts.register(fs)
+		parse(fs, args)
+		opts.run(context.Background())
+	case "version", "--version", "-v":
+		fmt.Println(version.String())
+	case "-h", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+}
+
+// newFlagSet builds a flag set for a subcommand with the shared logging and
+// configuration flags already registered.
+func newFlagSet(cmd string) *flag.FlagSet {
+	fs := flag.NewFlagSet("pvc-webhook "+cmd, flag.ExitOnError)
+	klog.InitFlags(fs)
+	fs.Bool("print-config", false, "print the fully resolved configuration as JSON and exit")
+	return fs
+}
+
+// parse resolves the subcommand's configuration with the layered precedence
+// described in config.go: flags beat environment variables, which beat the
+// config file.
+func parse(fs *flag.FlagSet, args []string) {
+	applyEnvDefaults(fs)
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+	if f := fs.Lookup("print-config"); f != nil && f.Value.String() == "true" {
+		// Load the secret store first so setting() resolves completely.
+		if sd := fs.Lookup("secrets-dir"); sd != nil {
+			initSecretStore(sd.Value.String())
+		}
+		printConfig(fs)
+		os.Exit(0)
+	}
+}
+
+// serveVersion writes the build metadata as JSON; both roles mount it next to
+// their metrics so operators can ask a running pod what release it is.
+func serveVersion(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(version.Get()); err != nil {
+		klog.Errorf("writing version response: %v", err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: pvc-webhook <subcommand> [flags]
+
+Subcommands:
+  webhook      run the mutating admission webhook
+  controller   run the claim controller
+  all          run the webhook and the controller in one process
+  install      create or update the cluster resources the webhook needs
+  uninstall    remove the installed cluster resources
+  version      print the version, git commit, and build date and exit
+
+Run "pvc-webhook <subcommand> -h" for the flags of each subcommand.
+`)
+}
@@ -0,0 +1,130 @@
+// The uninstall subcommand removes the cluster resources the install
+// subcommand created, with optional cleanup of the PersistentVolumeClaims the
+// controller manages.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// managedClaimSelector matches the claims created by the controller.
+const managedClaimSelector = "created-by=pvc-webhook"
+
+// uninstallOptions carries the uninstall subcommand's flag values.
+type uninstallOptions struct {
+	kubeconfig        string
+	namespace         string
+	serviceName       string
+	secretName        string
+	webhookName       string
+	deleteManagedPVCs bool
+	retainManagedPVCs bool
+	dryRun            bool
+}
+
+// register binds the uninstall flags to a flag set.
+func (o *uninstallOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "path to a kubeconfig; empty means in-cluster")
+	fs.StringVar(&o.namespace, "namespace", "pvc-webhook", "namespace the webhook runs in")
+	fs.StringVar(&o.serviceName, "service-name", "pvc-webhook", "name of the Service fronting the webhook")
+	fs.StringVar(&o.secretName, "secret-name", "pvc-webhook-certs", "name of the Secret holding the serving certificate")
+	fs.StringVar(&o.webhookName, "webhook-name", "pvc-webhook", "name of the MutatingWebhookConfiguration")
+	fs.BoolVar(&o.deleteManagedPVCs, "delete-managed-pvcs", false, "also delete the claims labeled "+managedClaimSelector+" in every namespace")
+	fs.BoolVar(&o.retainManagedPVCs, "retain-managed-pvcs", false, "explicitly keep the managed claims; this is the default")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "print what would be removed without removing anything")
+}
+
+// run removes the installed resources and reports each one.
+func (o *uninstallOptions) run(ctx context.Context) {
+	if o.deleteManagedPVCs && o.retainManagedPVCs {
+		klog.Fatal("--delete-managed-pvcs and --retain-managed-pvcs are mutually exclusive")
+	}
+	cfg, err := buildConfig(o.kubeconfig)
+	if err != nil {
+		klog.Fatalf("building client config: %v", err)
+	}
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("building clientset: %v", err)
+	}
+
+	// The webhook configuration goes first so no new pods are mutated while
+	// the rest is torn down.
+	o.delete("mutatingwebhookconfiguration", o.webhookName, func() error {
+		return client.AdmissionregistrationV1().MutatingWebhookConfigurations().Delete(ctx, o.webhookName, metav1.DeleteOptions{})
+	})
+	o.delete("service", o.serviceName, func() error {
+		return client.CoreV1().Services(o.namespace).Delete(ctx, o.serviceName, metav1.DeleteOptions{})
+	})
+	o.delete("secret", o.secretName, func() error {
+		return client.CoreV1().Secrets(o.namespace).Delete(ctx, o.secretName, metav1.DeleteOptions{})
+	})
+	o.delete("clusterrolebinding", "pvc-webhook", func() error {
+		return client.RbacV1().ClusterRoleBindings().Delete(ctx, "pvc-webhook", metav1.DeleteOptions{})
+	})
+	o.delete("clusterrole", "pvc-webhook", func() error {
+		return client.RbacV1().ClusterRoles().Delete(ctx, "pvc-webhook", metav1.DeleteOptions{})
+	})
+	o.delete("serviceaccount", "pvc-webhook", func() error {
+		return client.CoreV1().ServiceAccounts(o.namespace).Delete(ctx, "pvc-webhook", metav1.DeleteOptions{})
+	})
+
+	if err := o.handleManagedClaims(ctx, client); err != nil {
+		klog.Fatalf("handling managed claims: %v", err)
+	}
+}
+
+// delete removes one resource via fn, honoring dry-run and tolerating
+// resources that are already gone.
+func (o *uninstallOptions) delete(kind, name string, fn func() error) {
+	if o.dryRun {
+		report(kind, name, "deleted (dry run)")
+		return
+	}
+	switch err := fn(); {
+	case err == nil:
+		report(kind, name, "deleted")
+	case apierrors.IsNotFound(err):
+		report(kind, name, "not found")
+	default:
+		klog.Fatalf("deleting %s/%s: %v", kind, name, err)
+	}
+}
+
+// handleManagedClaims deletes or retains the claims the controller created,
+// depending on the flags. Without --delete-managed-pvcs the claims are kept
+// and only counted, so an operator can see what an uninstall leaves behind.
+func (o *uninstallOptions) handleManagedClaims(ctx context.Context, client kubernetes.Interface) error {
+	claims, err := client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedClaimSelector,
+	})
+	if err != nil {
+		return err
+	}
+	if len(claims.Items) == 0 {
+		return nil
+	}
+	if !o.deleteManagedPVCs {
+		fmt.Printf("%d managed claim(s) retained; rerun with --delete-managed-pvcs to remove them\n", len(claims.Items))
+		return nil
+	}
+	for _, claim := range claims.Items {
+		name := claim.Namespace + "/" + claim.Name
+		if o.dryRun {
+			report("persistentvolumeclaim", name, "deleted (dry run)")
+			continue
+		}
+		if err := client.CoreV1().PersistentVolumeClaims(claim.Namespace).Delete(ctx, claim.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		report("persistentvolumeclaim", name, "deleted")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Startup configuration validation. Every default is checked once at boot so
+// a typo in an environment variable or a missing certificate fails the rollout
+// with an actionable message, instead of surfacing as a denied admission or a
+// failed reconcile hours later.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	apiannotations "github.com/rkitindi-kr/pvc-webhook/pkg/apis/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/controller"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/webhook"
+)
+
+// validateWebhookConfig checks the webhook's environment-derived defaults.
+func validateWebhookConfig(cfg webhook.Config) error {
+	if err := checkQuantity("DEFAULT_SIZE", cfg.DefaultSize); err != nil {
+		return err
+	}
+	for _, m := range cfg.DefaultAccessModes {
+		if !apiannotations.ValidAccessMode(strings.TrimSpace(m)) {
+			return fmt.Errorf("DEFAULT_ACCESS_MODES: unknown access mode %q", strings.TrimSpace(m))
+		}
+	}
+	for env, value := range map[string]string{
+		"MIN_VOLUME_SIZE": cfg.MinimumSize,
+		"SIZE_FLOOR":      cfg.SizeFloor,
+		"SIZE_CEILING":    cfg.SizeCeiling,
+	} {
+		if value == "" {
+			continue
+		}
+		if err := checkQuantity(env, value); err != nil {
+			return err
+		}
+	}
+	if cfg.SizeFloor != "" && cfg.SizeCeiling != "" {
+		floor := resource.MustParse(cfg.SizeFloor)
+		ceiling := resource.MustParse(cfg.SizeCeiling)
+		if floor.Cmp(ceiling) > 0 {
+			return fmt.Errorf("SIZE_FLOOR %s exceeds SIZE_CEILING %s", cfg.SizeFloor, cfg.SizeCeiling)
+		}
+	}
+	return nil
+}
+
+// validateControllerConfig checks the controller's environment-derived
+// defaults.
+func validateControllerConfig(cfg controller.Config) error {
+	if err := checkQuantity("DEFAULT_SIZE", cfg.DefaultSize); err != nil {
+		return err
+	}
+	for class, size := range cfg.ClassDefaultSizes {
+		if _, err := resource.ParseQuantity(size); err != nil {
+			return fmt.Errorf("CLASS_DEFAULT_SIZES: invalid size %q for class %q: %v", size, class, err)
+		}
+	}
+	for name, profile := range cfg.Profiles {
+		if profile.Size != "" {
+			if _, err := resource.ParseQuantity(profile.Size); err != nil {
+				return fmt.Errorf("PROFILES: profile %q has invalid size %q: %v", name, profile.Size, err)
+			}
+		}
+		for _, m := range splitNonEmpty(profile.AccessModes) {
+			if !apiannotations.ValidAccessMode(m) {
+				return fmt.Errorf("PROFILES: profile %q has unknown access mode %q", name, m)
+			}
+		}
+	}
+	if !controller.ValidAdoptionPolicy(string(cfg.AdoptionPolicy)) {
+		return fmt.Errorf("--adoption-policy: unknown policy %q, want adopt, reuse-readonly, or error", cfg.AdoptionPolicy)
+	}
+	return nil
+}
+
+// validateTLSFiles confirms the certificate and key load as a pair before the
+// listener starts, so a bad mount fails the pod instead of every handshake.
+func validateTLSFiles(certFile, keyFile string) error {
+	if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		return fmt.Errorf("loading TLS key pair (--tls-cert-file=%s, --tls-key-file=%s): %v", certFile, keyFile, err)
+	}
+	return nil
+}
+
+// verifyStorageClass fails when the configured default storage class does not
+// exist. Transient API errors only warn; a flaky control plane at boot should
+// not crash-loop the deployment.
+func verifyStorageClass(ctx context.Context, client kubernetes.Interface, name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := client.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("DEFAULT_STORAGE_CLASS %q does not exist; create the StorageClass or change the setting", name)
+		}
+		klog.Warningf("could not verify storage class %q, continuing: %v", name, err)
+	}
+	return nil
+}
+
+// checkQuantity validates one quantity-valued setting.
+func checkQuantity(name, value string) error {
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return fmt.Errorf("%s: invalid quantity %q: %v", name, value, err)
+	}
+	return nil
+}
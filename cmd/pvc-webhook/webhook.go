@@ -0,0 +1,514 @@
+// The webhook subcommand runs the mutating admission webhook that converts
+// emptyDir volumes in admitted pods into PersistentVolumeClaim references.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/secrets"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/webhook"
+)
+
+// webhookOptions carries the webhook subcommand's flag values.
+type webhookOptions struct {
+	listenAddr           string
+	enableTLS            bool
+	tlsCertFile          string
+	tlsKeyFile           string
+	conversionPercentage int
+	namespacePercentages string
+	killSwitchConfigMap  string
+	tlsMinVersion        string
+	tlsMaxVersion        string
+	tlsCipherSuites      string
+	fips                 bool
+	clientCAFile         string
+	allowedClientCNs     string
+	maxRequestBytes      int64
+	readHeaderTimeout    time.Duration
+	readTimeout          time.Duration
+	writeTimeout         time.Duration
+	idleTimeout          time.Duration
+	healthProbeAddr      string
+	metricsAddr          string
+	secretsDir           string
+	maxConcurrentReviews int
+	maxVolumesPerPod     int
+	rejectExcessVolumes  bool
+	maxMetricNamespaces  int
+	conversionRecords    bool
+}
+
+// register binds the webhook flags to a flag set, so the webhook and all
+// subcommands share one definition.
+func (o *webhookOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.listenAddr, "listen-addr", ":8443", "address the webhook server listens on")
+	fs.BoolVar(&o.enableTLS, "tls", true, "serve TLS; disable only behind a sidecar that terminates TLS or for local development")
+	fs.StringVar(&o.tlsCertFile, "tls-cert-file", "/etc/webhook/certs/tls.crt", "path to the TLS certificate")
+	fs.StringVar(&o.tlsKeyFile, "tls-key-file", "/etc/webhook/certs/tls.key", "path to the TLS private key")
+	fs.IntVar(&o.conversionPercentage, "conversion-percentage", 100, "percentage of eligible objects to convert, for canary rollouts")
+	fs.StringVar(&o.namespacePercentages, "namespace-conversion-percentages", "", "per-namespace overrides as ns=pct pairs, comma separated")
+	fs.StringVar(&o.killSwitchConfigMap, "kill-switch-configmap", "", "namespace/name of a ConfigMap whose mutations-enabled key acts as a runtime kill switch")
+	fs.StringVar(&o.tlsMinVersion, "tls-min-version", "1.2", "minimum accepted TLS version: 1.2 or 1.3")
+	fs.StringVar(&o.tlsMaxVersion, "tls-max-version", "", "maximum accepted TLS version; empty means no cap")
+	fs.StringVar(&o.tlsCipherSuites, "tls-cipher-suites", "", "comma-separated TLS 1.2 cipher suite names; empty uses the Go defaults")
+	fs.BoolVar(&o.fips, "fips", false, "restrict TLS to a FIPS 140-2 compatible profile, overriding the other TLS tuning flags")
+	fs.StringVar(&o.clientCAFile, "client-ca-file", "", "CA bundle used to verify client certificates; empty disables client authentication")
+	fs.StringVar(&o.allowedClientCNs, "allowed-client-cns", "", "comma-separated common names accepted from client certificates; empty accepts any verified client")
+	fs.Int64Var(&o.maxRequestBytes, "max-request-bytes", 3*1024*1024, "largest accepted request body in bytes; 0 disables the limit")
+	fs.DurationVar(&o.readHeaderTimeout, "read-header-timeout", 5*time.Second, "maximum time to read request headers")
+	fs.DurationVar(&o.readTimeout, "read-timeout", 10*time.Second, "maximum time to read an entire request")
+	fs.DurationVar(&o.writeTimeout, "write-timeout", 10*time.Second, "maximum time to write a response")
+	fs.DurationVar(&o.idleTimeout, "idle-timeout", 90*time.Second, "maximum time an idle keep-alive connection is held open")
+	fs.StringVar(&o.healthProbeAddr, "health-probe-addr", ":8081", "address the manager serves health probes on")
+	fs.IntVar(&o.maxConcurrentReviews, "max-concurrent-reviews", 0, "cap on admission requests handled at once; 0 disables the limit")
+	fs.IntVar(&o.maxVolumesPerPod, "max-volumes-per-pod", 0, "cap on converted volumes per pod; 0 disables the limit")
+	fs.BoolVar(&o.rejectExcessVolumes, "reject-excess-volumes", false, "deny pods exceeding --max-volumes-per-pod instead of converting only the first volumes")
+	fs.IntVar(&o.maxMetricNamespaces, "max-metric-namespaces", 0, "cap on distinct namespaces in per-namespace metrics, folding the rest into \"other\"; 0 tracks all")
+	fs.BoolVar(&o.conversionRecords, "audit-conversion-records", false, "persist a ConversionRecord object per converted volume as an audit trail")
+	// The all subcommand registers the controller's flags first; both roles
+	// then share its metrics listener, which serves the process-wide
+	// registry.
+	if fs.Lookup("metrics-addr") == nil {
+		fs.StringVar(&o.metricsAddr, "metrics-addr", ":9090", "address serving Prometheus metrics; empty disables the listener")
+	}
+	if fs.Lookup("secrets-dir") == nil {
+		fs.StringVar(&o.secretsDir, "secrets-dir", "", "directory of a mounted Secret whose files override same-named environment settings")
+	}
+}
+
+// run starts the webhook and blocks until it exits.
+func (o *webhookOptions) run() {
+	initSecretStore(o.secretsDir)
+	metrics.SetNamespaceCardinalityLimit(o.maxMetricNamespaces)
+	cfg := webhook.Config{
+		DefaultSize:         envOr("DEFAULT_SIZE", "2Gi"),
+		DefaultStorageClass: setting("DEFAULT_STORAGE_CLASS"),
+		DefaultAccessModes:  strings.Split(envOr("DEFAULT_ACCESS_MODES", "ReadWriteOnce"), ","),
+	}
+	if v := setting("EXCLUDED_NAMESPACES"); v != "" {
+		cfg.ExcludedNamespaces = strings.Split(v, ",")
+	}
+	cfg.VolumeNamePatterns = splitNonEmpty(setting("VOLUME_NAME_PATTERNS"))
+	cfg.MinimumSize = setting("MIN_VOLUME_SIZE")
+	cfg.ExcludedVolumeNames = splitNonEmpty(setting("EXCLUDED_VOLUME_NAMES"))
+	cfg.ExcludedImages = splitNonEmpty(setting("EXCLUDED_IMAGES"))
+	cfg.StrictValidation = setting("STRICT_VALIDATION") == "true"
+	cfg.ShadowMode = setting("SHADOW_MODE") == "true"
+	cfg.SchedulingGate = setting("SCHEDULING_GATE") == "true"
+	cfg.SyncCreate = setting("SYNC_CREATE") == "true"
+	cfg.ClaimLabels = parseKeyValues(setting("CLAIM_LABELS"))
+	cfg.NamespaceTierLabel = setting("NAMESPACE_TIER_LABEL")
+	cfg.NamespaceTierClasses = parseKeyValues(setting("NAMESPACE_TIER_CLASSES"))
+	cfg.SensitiveNamespaceLabel = setting("SENSITIVE_NAMESPACE_LABEL")
+	cfg.EncryptedStorageClasses = splitNonEmpty(setting("ENCRYPTED_STORAGE_CLASSES"))
+	cfg.SizeFloor = setting("SIZE_FLOOR")
+	cfg.SizeCeiling = setting("SIZE_CEILING")
+	cfg.ConversionPercentage = o.conversionPercentage
+	cfg.NamespacePercentages = parsePercentages(o.namespacePercentages)
+	cfg.MaxConcurrentReviews = o.maxConcurrentReviews
+	cfg.MaxVolumesPerPod = o.maxVolumesPerPod
+	cfg.RejectExcessVolumes = o.rejectExcessVolumes
+	cfg.ConversionRecords = o.conversionRecords
+	cfg.PreviewAuthToken = setting("PREVIEW_AUTH_TOKEN")
+	cfg.PolicyURL = setting("POLICY_URL")
+	cfg.PolicyFailClosed = setting("POLICY_FAIL_CLOSED") == "true"
+	if v := setting("POLICY_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err != nil {
+			klog.Warningf("ignoring malformed POLICY_TIMEOUT %q: %v", v, err)
+		} else {
+			cfg.PolicyTimeout = d
+		}
+	}
+	if v := setting("CEL_RULES"); v != "" {
+		var rules []webhook.CELRule
+		if err := json.Unmarshal([]byte(v), &rules); err != nil {
+			klog.Warningf("ignoring malformed CEL_RULES: %v", err)
+		} else {
+			cfg.CELRules = rules
+		}
+	}
+	if err := validateWebhookConfig(cfg); err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
+
+	srv := webhook.NewServer(cfg)
+	restCfg := clusterConfig()
+	if restCfg != nil {
+		if client, err := kubernetes.NewForConfig(restCfg); err != nil {
+			klog.Warningf("building clientset, cluster features disabled: %v", err)
+		} else {
+			if err := verifyStorageClass(context.Background(), client, cfg.DefaultStorageClass); err != nil {
+				klog.Fatalf("invalid configuration: %v", err)
+			}
+			srv.SetClient(client)
+			srv.SetEventRecorder(buildEventRecorder(client))
+			if dyn, err := dynamic.NewForConfig(restCfg); err != nil {
+				klog.Warningf("building dynamic client, StorageLimitRange enforcement and conversion records disabled: %v", err)
+			} else {
+				srv.SetDynamicClient(dyn)
+			}
+			// The namespace informer also backs the pause annotation, so it
+			// runs whenever a client is available.
+			srv.StartNamespaceInformer(context.Background(), client)
+			if ns, name, ok := splitConfigMapRef(o.killSwitchConfigMap); ok {
+				ks := &webhook.KillSwitch{}
+				go ks.Watch(context.Background(), client, ns, name)
+				srv.SetKillSwitch(ks)
+			}
+		}
+	}
+	handler := limitRequestBody(srv.Handler(), o.maxRequestBytes)
+
+	// Metrics are served on their own plaintext listener, independent of the
+	// TLS admission port, so Prometheus needs no client certificate.
+	if o.metricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler())
+			mux.HandleFunc("/version", serveVersion)
+			if err := http.ListenAndServe(o.metricsAddr, mux); err != nil {
+				klog.Errorf("metrics listener failed: %v", err)
+			}
+		}()
+	}
+
+	if !o.enableTLS {
+		// The API server refuses plaintext webhooks, so this only makes sense
+		// behind a TLS-terminating sidecar or for local testing. Warn loudly
+		// so a misconfigured production deployment is obvious from the logs.
+		// Conservative timeouts keep a slow client from tying up the webhook.
+		klog.Warning("TLS IS DISABLED: serving /mutate over plain HTTP; every client on the network path can read and forge admission traffic")
+		httpServer := &http.Server{
+			Addr:              o.listenAddr,
+			Handler:           handler,
+			ReadHeaderTimeout: o.readHeaderTimeout,
+			ReadTimeout:       o.readTimeout,
+			WriteTimeout:      o.writeTimeout,
+			IdleTimeout:       o.idleTimeout,
+		}
+		klog.Infof("webhook listening on %s (plain HTTP)", o.listenAddr)
+		if err := httpServer.ListenAndServe(); err != nil {
+			klog.Fatalf("webhook server failed: %v", err)
+		}
+		return
+	}
+	if restCfg == nil {
+		klog.Fatal("no cluster configuration available; use --tls=false for local development")
+	}
+	if err := validateTLSFiles(o.tlsCertFile, o.tlsKeyFile); err != nil {
+		klog.Fatalf("invalid configuration: %v", err)
+	}
+
+	// The manager's webhook server brings certificate rotation via its
+	// certwatcher and a uniform lifecycle, instead of a hand-rolled listener
+	// that must be restarted when certs renew.
+	tlsConfig, err := buildTLSConfig(o.tlsMinVersion, o.tlsMaxVersion, splitNonEmpty(o.tlsCipherSuites), o.fips)
+	if err != nil {
+		klog.Fatalf("configuring TLS: %v", err)
+	}
+	if o.clientCAFile != "" {
+		if err := addClientAuth(tlsConfig, o.clientCAFile, splitNonEmpty(o.allowedClientCNs)); err != nil {
+			klog.Fatalf("configuring client certificate authentication: %v", err)
+		}
+	}
+	port, err := listenPort(o.listenAddr)
+	if err != nil {
+		klog.Fatalf("parsing --listen-addr: %v", err)
+	}
+	certDir, certName := filepath.Split(o.tlsCertFile)
+	keyDir, keyName := filepath.Split(o.tlsKeyFile)
+	if certDir != keyDir {
+		klog.Fatal("--tls-cert-file and --tls-key-file must live in the same directory")
+	}
+
+	mgr, err := manager.New(restCfg, manager.Options{
+		WebhookServer: crwebhook.NewServer(crwebhook.Options{
+			Port:     port,
+			CertDir:  certDir,
+			CertName: certName,
+			KeyName:  keyName,
+			TLSOpts: []func(*tls.Config){func(c *tls.Config) {
+				c.MinVersion = tlsConfig.MinVersion
+				c.MaxVersion = tlsConfig.MaxVersion
+				c.CipherSuites = tlsConfig.CipherSuites
+				c.ClientCAs = tlsConfig.ClientCAs
+				c.ClientAuth = tlsConfig.ClientAuth
+				c.VerifyPeerCertificate = tlsConfig.VerifyPeerCertificate
+			}},
+		}),
+		Metrics:                metricsserver.Options{BindAddress: "0"},
+		HealthProbeBindAddress: o.healthProbeAddr,
+	})
+	if err != nil {
+		klog.Fatalf("building manager: %v", err)
+	}
+	mgr.GetWebhookServer().Register("/", handler)
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		klog.Fatalf("registering health check: %v", err)
+	}
+	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+		klog.Fatalf("registering readiness check: %v", err)
+	}
+
+	klog.Infof("webhook listening on %s", o.listenAddr)
+	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+		klog.Fatalf("webhook manager failed: %v", err)
+	}
+}
+
+// listenPort extracts the port from a host:port listen address.
+func listenPort(addr string) (int, error) {
+	_, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(portStr)
+}
+
+// fipsCipherSuites are the TLS 1.2 suites permitted under the FIPS 140-2
+// profile; TLS 1.3 suites are fixed by the standard library and all allowed.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+}
+
+// buildTLSConfig translates the TLS tuning flags into a configuration. The
+// FIPS preset wins over the individual flags so a single switch yields a
+// compliant listener.
+func buildTLSConfig(minVersion, maxVersion string, cipherNames []string, fips bool) (*tls.Config, error) {
+	if fips {
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			CipherSuites: fipsCipherSuites,
+		}, nil
+	}
+	cfg := &tls.Config{}
+	var err error
+	if cfg.MinVersion, err = parseTLSVersion(minVersion); err != nil {
+		return nil, err
+	}
+	if maxVersion != "" {
+		if cfg.MaxVersion, err = parseTLSVersion(maxVersion); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range cipherNames {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite %q", name)
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+	return cfg, nil
+}
+
+// parseTLSVersion maps "1.2"/"1.3" onto the tls version constants.
+func parseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q, want 1.2 or 1.3", v)
+}
+
+// cipherSuiteID resolves a cipher suite name against the suites the standard
+// library implements, insecure ones deliberately excluded.
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// addClientAuth requires clients to present a certificate signed by the given
+// CA, optionally restricted to a set of common names. In practice the CA is
+// the cluster's aggregation or webhook CA, so only the kube-apiserver can
+// invoke the admission endpoints.
+func addClientAuth(cfg *tls.Config, caFile string, allowedCNs []string) error {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return fmt.Errorf("reading client CA bundle: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in %s", caFile)
+	}
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	if len(allowedCNs) > 0 {
+		cfg.VerifyPeerCertificate = func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+			for _, chain := range verifiedChains {
+				for _, cn := range allowedCNs {
+					if chain[0].Subject.CommonName == cn {
+						return nil
+					}
+				}
+			}
+			return fmt.Errorf("client certificate common name not in allowed set")
+		}
+	}
+	return nil
+}
+
+// splitNonEmpty splits a comma-separated list, dropping empty entries.
+func splitNonEmpty(value string) []string {
+	var out []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// limitRequestBody caps the request body size so oversized payloads fail with
+// 413 instead of exhausting memory. A limit of 0 leaves bodies unbounded.
+func limitRequestBody(next http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clusterConfig returns a client configuration for the cluster, or nil when
+// none is available (e.g. local development). The webhook degrades gracefully
+// without one: events and the kill switch are disabled.
+func clusterConfig() *rest.Config {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+			cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		}
+	}
+	if err != nil || cfg == nil {
+		klog.Warningf("no client configuration, cluster features disabled: %v", err)
+		return nil
+	}
+	return cfg
+}
+
+// buildEventRecorder wires an event recorder against the cluster.
+func buildEventRecorder(client kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "pvc-webhook"})
+}
+
+// splitConfigMapRef parses a "namespace/name" ConfigMap reference.
+func splitConfigMapRef(ref string) (namespace, name string, ok bool) {
+	if ref == "" {
+		return "", "", false
+	}
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		klog.Warningf("ignoring malformed configmap reference %q, want namespace/name", ref)
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// parsePercentages parses "ns1=50,ns2=10" into a map, ignoring bad entries.
+func parsePercentages(value string) map[string]int {
+	if value == "" {
+		return nil
+	}
+	out := map[string]int{}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		pct, err := strconv.Atoi(parts[1])
+		if err != nil {
+			klog.Warningf("ignoring invalid namespace percentage %q", pair)
+			continue
+		}
+		out[parts[0]] = pct
+	}
+	return out
+}
+
+// secretStore, when configured via --secrets-dir, overrides environment
+// variables with values from a mounted Secret.
+var (
+	secretStore     *secrets.Store
+	secretStoreOnce sync.Once
+)
+
+// initSecretStore loads the mounted Secret directory and starts its reload
+// loop, letting sensitive settings live in a Secret instead of the pod env.
+// It runs at most once per process; the all subcommand's two roles share one
+// store.
+func initSecretStore(dir string) {
+	if dir == "" {
+		return
+	}
+	secretStoreOnce.Do(func() {
+		store, err := secrets.NewStore(dir)
+		if err != nil {
+			klog.Fatalf("loading secrets from %s: %v", dir, err)
+		}
+		go store.Watch(context.Background(), time.Minute)
+		secretStore = store
+	})
+}
+
+// setting returns a configuration value, preferring the mounted secret store,
+// then the environment, then the config file (see config.go).
+func setting(key string) string {
+	if secretStore != nil {
+		if v := secretStore.Get(key); v != "" {
+			return v
+		}
+	}
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fileSetting(key)
+}
+
+func envOr(key, fallback string) string {
+	if v := setting(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,17 @@
+module github.com/rkitindi-kr/pvc-webhook
+
+go 1.22
+
+require (
+	github.com/evanphx/json-patch/v5 v5.9.0
+	github.com/google/cel-go v0.17.8
+	github.com/prometheus/client_golang v1.19.1
+	gomodules.xyz/jsonpatch/v2 v2.4.0
+	golang.org/x/time v0.5.0
+	k8s.io/api v0.30.3
+	k8s.io/apiextensions-apiserver v0.30.3
+	k8s.io/apimachinery v0.30.3
+	k8s.io/client-go v0.30.3
+	k8s.io/klog/v2 v2.120.1
+	sigs.k8s.io/controller-runtime v0.18.4
+)
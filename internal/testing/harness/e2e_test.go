@@ -0,0 +1,40 @@
+package harness
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/controller"
+)
+
+// TestConvertedPodClaimBound walks the core controller path end to end: a pod
+// carrying the webhook's conversion annotations appears, the controller
+// creates its claim with the annotated size, and the claim reaches Bound.
+func TestConvertedPodClaimBound(t *testing.T) {
+	h := Start(t, controller.Config{
+		DefaultSize: "1Gi",
+	})
+	ns := h.Namespace(t)
+
+	pod := ConvertedPod(ns, "web-0", "cache", "web-0-cache", map[string]string{
+		annotations.Size: "2Gi",
+	})
+	h.CreatePod(t, pod)
+
+	pvc := h.WaitForClaim(t, ns, "web-0-cache")
+	if got := pvc.Labels["created-by"]; got != "pvc-webhook" {
+		t.Errorf("claim label created-by = %q, want %q", got, "pvc-webhook")
+	}
+	want := resource.MustParse("2Gi")
+	if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.Cmp(want) != 0 {
+		t.Errorf("claim storage request = %s, want %s", got.String(), want.String())
+	}
+
+	// envtest runs no provisioner, so stand in for one and confirm the claim
+	// settles in Bound.
+	h.BindClaim(t, ns, "web-0-cache")
+	h.WaitForClaimPhase(t, ns, "web-0-cache", corev1.ClaimBound)
+}
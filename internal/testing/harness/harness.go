@@ -0,0 +1,171 @@
+// Package harness boots an envtest control plane and runs the controller
+// against it, so controller changes can be integration-tested without a real
+// cluster. Tests are skipped unless KUBEBUILDER_ASSETS points at the envtest
+// binaries (install with `setup-envtest use`).
+package harness
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/controller"
+)
+
+// Harness is a running envtest control plane with the controller attached.
+type Harness struct {
+	// Client talks to the envtest API server.
+	Client kubernetes.Interface
+	// RESTConfig is the config the harness built its client from, for tests
+	// that need their own clients (e.g. dynamic).
+	RESTConfig *rest.Config
+}
+
+// Start boots envtest, runs the controller with the given config in the
+// background, and registers cleanup on the test. It skips the test when the
+// envtest binaries are not installed, so `go test ./...` stays green on
+// machines without them.
+func Start(t *testing.T, cfg controller.Config) *Harness {
+	t.Helper()
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS not set; install envtest binaries with `setup-envtest use` to run integration tests")
+	}
+
+	env := &envtest.Environment{}
+	restCfg, err := env.Start()
+	if err != nil {
+		t.Fatalf("starting envtest: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := env.Stop(); err != nil {
+			t.Errorf("stopping envtest: %v", err)
+		}
+	})
+
+	client, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		t.Fatalf("building clientset: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go controller.New(client, cfg).Run(ctx)
+
+	return &Harness{Client: client, RESTConfig: restCfg}
+}
+
+// Namespace creates a fresh test namespace and returns its generated name.
+func (h *Harness) Namespace(t *testing.T) string {
+	t.Helper()
+	ns, err := h.Client.CoreV1().Namespaces().Create(context.Background(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "pvc-webhook-test-"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("creating namespace: %v", err)
+	}
+	return ns.Name
+}
+
+// ConvertedPod returns a pod shaped the way the webhook leaves it: the volume
+// already rewritten into a claim reference and the conversion annotations
+// stamped. params are extra per-volume parameters, keyed by parameter name
+// (e.g. annotations.Size).
+func ConvertedPod(namespace, name, volume, claim string, params map[string]string) *corev1.Pod {
+	anns := map[string]string{
+		annotations.Key(volume, annotations.Converted): "true",
+		annotations.Key(volume, annotations.Claim):     claim,
+	}
+	for param, value := range params {
+		anns[annotations.Key(volume, param)] = value
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Annotations: anns,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Image: "registry.k8s.io/pause:3.9",
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      volume,
+					MountPath: "/data",
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: volume,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+				},
+			}},
+		},
+	}
+}
+
+// CreatePod creates the pod, failing the test on error.
+func (h *Harness) CreatePod(t *testing.T, pod *corev1.Pod) {
+	t.Helper()
+	if _, err := h.Client.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
+
+// WaitForClaim polls until the named claim exists and returns it.
+func (h *Harness) WaitForClaim(t *testing.T, namespace, name string) *corev1.PersistentVolumeClaim {
+	t.Helper()
+	var pvc *corev1.PersistentVolumeClaim
+	err := wait.PollUntilContextTimeout(context.Background(), 250*time.Millisecond, 30*time.Second, true,
+		func(ctx context.Context) (bool, error) {
+			got, err := h.Client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			pvc = got
+			return true, nil
+		})
+	if err != nil {
+		t.Fatalf("claim %s/%s was not created: %v", namespace, name, err)
+	}
+	return pvc
+}
+
+// BindClaim marks the claim Bound through its status subresource, standing in
+// for the volume provisioner envtest does not run.
+func (h *Harness) BindClaim(t *testing.T, namespace, name string) {
+	t.Helper()
+	ctx := context.Background()
+	pvc, err := h.Client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting claim %s/%s: %v", namespace, name, err)
+	}
+	pvc.Status.Phase = corev1.ClaimBound
+	if _, err := h.Client.CoreV1().PersistentVolumeClaims(namespace).UpdateStatus(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("binding claim %s/%s: %v", namespace, name, err)
+	}
+}
+
+// WaitForClaimPhase polls until the claim reports the given phase.
+func (h *Harness) WaitForClaimPhase(t *testing.T, namespace, name string, phase corev1.PersistentVolumeClaimPhase) {
+	t.Helper()
+	err := wait.PollUntilContextTimeout(context.Background(), 250*time.Millisecond, 30*time.Second, true,
+		func(ctx context.Context) (bool, error) {
+			pvc, err := h.Client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			return pvc.Status.Phase == phase, nil
+		})
+	if err != nil {
+		t.Fatalf("claim %s/%s never reached phase %s: %v", namespace, name, phase, err)
+	}
+}
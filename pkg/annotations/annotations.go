@@ -0,0 +1,145 @@
+// Package annotations defines the canonical annotation schema shared by the
+// webhook and the controller. Per-volume keys are of the form
+// pvc-webhook.vol/<volume-name>.<param> so a single pod can configure each of
+// its volumes independently. The retired single-volume keys written by early
+// releases (pvc-webhook/claim, pvc-webhook/storage-size, ...) are still
+// accepted on read during migration.
+package annotations
+
+import "strings"
+
+const (
+	// Prefix starts every canonical per-volume parameter key.
+	Prefix = "pvc-webhook.vol/"
+	// legacyPrefix starts the retired single-volume keys.
+	legacyPrefix = "pvc-webhook/"
+)
+
+// NamespacePaused, set to "true" on a Namespace, suspends conversions and
+// controller actions for that namespace until the annotation is removed.
+const NamespacePaused = legacyPrefix + "paused"
+
+// WebhookVersion records on converted pods which webhook release performed
+// the mutation.
+const WebhookVersion = legacyPrefix + "webhook-version"
+
+// MutatedBy and AdmissionUID correlate a converted pod with the webhook
+// release and the admission request that rewrote it; MutatedBy is
+// "<version>@<RFC3339 time>".
+const (
+	MutatedBy    = legacyPrefix + "mutated-by"
+	AdmissionUID = legacyPrefix + "admission-uid"
+)
+
+// Per-volume parameter names appended to Prefix + volume name.
+const (
+	// Converted marks a single volume as already rewritten, keeping the
+	// webhook idempotent across reinvocations.
+	Converted = "converted"
+	// Claim records the claim name chosen for a converted volume.
+	Claim = "claim"
+	// Size, StorageClass and AccessModes are the user-facing claim
+	// parameters read by the controller.
+	Size         = "size"
+	StorageClass = "storage-class"
+	AccessModes  = "access-modes"
+	// Profile selects a named storage profile (class, size, access modes,
+	// reclaim policy) defined by the operator, instead of raw parameters.
+	Profile = "profile"
+	// Identity names a stable claim identity shared by unrelated pods.
+	Identity = "identity"
+	// Shared requests one ReadWriteMany claim for all workload replicas.
+	Shared = "shared"
+	// TopologyPin opts the claim into topology pinning after scheduling.
+	TopologyPin = "topology-pin"
+	// CSIParams passes driver-specific annotations through to the claim.
+	CSIParams = "csi-params"
+	// DataSource prepopulates the claim from "[apiGroup/]Kind/name".
+	DataSource = "data-source"
+	// DataSourceNamespace points the data source at another namespace,
+	// subject to a ReferenceGrant there and the controller's feature gate.
+	DataSourceNamespace = "data-source-namespace"
+	// FromSnapshot restores the claim from a named VolumeSnapshot; it is
+	// shorthand for a snapshot.storage.k8s.io/VolumeSnapshot data source.
+	FromSnapshot = "fromSnapshot"
+	// VolumeName pins the claim to a pre-provisioned PersistentVolume.
+	VolumeName = "volumeName"
+	// Selector restricts binding to PersistentVolumes carrying the given
+	// labels, as a JSON object, for manually provisioned storage pools.
+	Selector = "selector"
+	// VolumeMode requests Filesystem or Block for the claim.
+	VolumeMode = "volumeMode"
+	// ReclaimPolicy is applied to the bound PersistentVolume.
+	ReclaimPolicy = "reclaimPolicy"
+	// VolumeAttributesClass selects a VolumeAttributesClass for the claim.
+	VolumeAttributesClass = "volumeAttributesClass"
+	// KeepLast retains only the newest N claims of the owning workload for
+	// this volume, garbage-collecting older generations.
+	KeepLast = "keepLast"
+	// TTLAfterPodDeletion keeps the claim for this duration after its pod
+	// is deleted before the sweeper removes it, e.g. "24h".
+	TTLAfterPodDeletion = "ttlAfterPodDeletion"
+	// NFSServer and NFSPath request a static NFS-backed PV/PVC pair.
+	NFSServer = "nfs.server"
+	NFSPath   = "nfs.path"
+	// StorageClassParams requests a derived StorageClass with the given
+	// parameter overrides.
+	StorageClassParams = "storage-class-params"
+	// Template names a PVCTemplate in the pod's namespace whose spec the
+	// claim starts from; other annotations override individual parameters.
+	Template = "template"
+)
+
+// legacyNames maps canonical parameter names onto their legacy single-volume
+// spellings, where those existed.
+var legacyNames = map[string]string{
+	Claim:        "claim",
+	Size:         "storage-size",
+	StorageClass: "storage-class",
+	AccessModes:  "access-modes",
+	// pvc-webhook/profile is pod-wide rather than legacy, letting one key
+	// select a profile for every volume.
+	Profile: "profile",
+}
+
+// Key returns the canonical annotation key for a volume parameter, e.g.
+// Key("cache", Size) == "pvc-webhook.vol/cache.size".
+func Key(volume, param string) string {
+	return Prefix + volume + "." + param
+}
+
+// Get returns a volume parameter, falling back to the legacy single-volume
+// key when the canonical one is absent. Legacy keys carry no volume name, so
+// their value applies to whichever volume is asked about.
+func Get(annotations map[string]string, volume, param string) string {
+	if v, ok := annotations[Key(volume, param)]; ok {
+		return v
+	}
+	if legacy, ok := legacyNames[param]; ok {
+		return annotations[legacyPrefix+legacy]
+	}
+	return ""
+}
+
+// Claims returns the volume-to-claim mapping recorded in the annotations. A
+// pod carrying only the legacy pvc-webhook/claim key maps its single volume,
+// named by pvc-webhook/volume or "data" when unnamed.
+func Claims(annotations map[string]string) map[string]string {
+	out := map[string]string{}
+	for key, claim := range annotations {
+		if strings.HasPrefix(key, Prefix) && strings.HasSuffix(key, "."+Claim) {
+			volume := strings.TrimSuffix(strings.TrimPrefix(key, Prefix), "."+Claim)
+			out[volume] = claim
+		}
+	}
+	if len(out) == 0 {
+		if claim := annotations[legacyPrefix+"claim"]; claim != "" {
+			volume := annotations[legacyPrefix+"volume"]
+			if volume == "" {
+				volume = "data"
+			}
+			out[volume] = claim
+		}
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package annotations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Size annotations accept human spellings on top of canonical quantities:
+// whitespace around or inside the value ("10 GiB") and the B-suffixed unit
+// names ("GiB", "GB"). Canonical inputs parse unchanged, so "10m" keeps its
+// quantity meaning and nothing silently reinterprets valid values.
+
+// friendlySize matches a number followed by a B-suffixed unit, after spaces
+// are removed.
+var friendlySize = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([a-zA-Z]+)$`)
+
+// friendlyUnits maps lower-cased B-suffixed unit spellings onto canonical
+// quantity suffixes.
+var friendlyUnits = map[string]string{
+	"b":   "",
+	"kb":  "k",
+	"mb":  "M",
+	"gb":  "G",
+	"tb":  "T",
+	"pb":  "P",
+	"eb":  "E",
+	"kib": "Ki",
+	"mib": "Mi",
+	"gib": "Gi",
+	"tib": "Ti",
+	"pib": "Pi",
+	"eib": "Ei",
+}
+
+// ParseSize parses a size value, accepting canonical resource quantities plus
+// the friendly spellings above. The returned quantity's String() is the
+// canonical form to reflect back to users. The error names the accepted
+// formats so an invalid annotation is self-explaining.
+func ParseSize(value string) (resource.Quantity, error) {
+	trimmed := strings.TrimSpace(value)
+	if q, err := resource.ParseQuantity(trimmed); err == nil {
+		return q, nil
+	}
+	compact := strings.ReplaceAll(trimmed, " ", "")
+	if m := friendlySize.FindStringSubmatch(compact); m != nil {
+		if suffix, ok := friendlyUnits[strings.ToLower(m[2])]; ok {
+			if q, err := resource.ParseQuantity(m[1] + suffix); err == nil {
+				return q, nil
+			}
+		}
+	}
+	return resource.Quantity{}, fmt.Errorf(
+		"invalid size %q: accepted formats are plain bytes (1073741824), decimal units (10G, 10GB), or binary units (10Gi, 10 GiB)", value)
+}
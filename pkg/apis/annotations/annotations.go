@@ -0,0 +1,234 @@
+// Package annotations is the public, typed view of the pvc-webhook annotation
+// schema for external tooling: admission frameworks embedding pkg/mutator,
+// CLIs inspecting pods, and tests. It wraps the canonical key handling in
+// pkg/annotations — which the webhook and controller share internally — with a
+// parsed parameter struct and a validator, so consumers never spell annotation
+// keys as string literals.
+package annotations
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	schema "github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Prefix starts every canonical per-volume parameter key.
+const Prefix = schema.Prefix
+
+// Parameter names appended to Prefix + volume name, re-exported from the
+// canonical schema so external consumers need only this package.
+const (
+	ParamConverted             = schema.Converted
+	ParamClaim                 = schema.Claim
+	ParamSize                  = schema.Size
+	ParamStorageClass          = schema.StorageClass
+	ParamAccessModes           = schema.AccessModes
+	ParamProfile               = schema.Profile
+	ParamIdentity              = schema.Identity
+	ParamShared                = schema.Shared
+	ParamTopologyPin           = schema.TopologyPin
+	ParamCSIParams             = schema.CSIParams
+	ParamDataSource            = schema.DataSource
+	ParamDataSourceNamespace   = schema.DataSourceNamespace
+	ParamFromSnapshot          = schema.FromSnapshot
+	ParamVolumeName            = schema.VolumeName
+	ParamSelector              = schema.Selector
+	ParamVolumeMode            = schema.VolumeMode
+	ParamReclaimPolicy         = schema.ReclaimPolicy
+	ParamVolumeAttributesClass = schema.VolumeAttributesClass
+	ParamKeepLast              = schema.KeepLast
+	ParamTTLAfterPodDeletion   = schema.TTLAfterPodDeletion
+	ParamNFSServer             = schema.NFSServer
+	ParamNFSPath               = schema.NFSPath
+	ParamStorageClassParams    = schema.StorageClassParams
+	ParamTemplate              = schema.Template
+)
+
+// Key returns the canonical annotation key for a volume parameter, e.g.
+// Key("cache", ParamSize) == "pvc-webhook.vol/cache.size".
+func Key(volume, param string) string {
+	return schema.Key(volume, param)
+}
+
+// VolumeParams holds every per-volume parameter a pod can set, parsed from its
+// annotations. String fields carry the raw annotation values; call Validate to
+// check they parse. Zero values mean the parameter is unset.
+type VolumeParams struct {
+	// Volume is the pod volume the parameters apply to.
+	Volume string
+	// Claim overrides the derived claim name.
+	Claim string
+	// Size is the requested storage quantity, e.g. "10Gi".
+	Size string
+	// StorageClass overrides the default storage class.
+	StorageClass string
+	// AccessModes are the requested access modes, long or short spellings.
+	AccessModes []string
+	// Profile selects a named operator-defined storage profile.
+	Profile string
+	// Identity names a stable claim identity shared by unrelated pods.
+	Identity string
+	// Shared requests one ReadWriteMany claim for all workload replicas.
+	Shared bool
+	// TopologyPin opts the claim into topology pinning after scheduling.
+	TopologyPin bool
+	// CSIParams passes driver-specific annotations through to the claim.
+	CSIParams string
+	// DataSource prepopulates the claim from "[apiGroup/]Kind/name".
+	DataSource string
+	// DataSourceNamespace resolves the data source in another namespace.
+	DataSourceNamespace string
+	// FromSnapshot restores the claim from a named VolumeSnapshot.
+	FromSnapshot string
+	// VolumeName pins the claim to a pre-provisioned PersistentVolume.
+	VolumeName string
+	// Selector restricts binding to PVs carrying these labels (JSON object).
+	Selector string
+	// VolumeMode requests Filesystem or Block for the claim.
+	VolumeMode string
+	// ReclaimPolicy is applied to the bound PersistentVolume.
+	ReclaimPolicy string
+	// VolumeAttributesClass selects a VolumeAttributesClass for the claim.
+	VolumeAttributesClass string
+	// KeepLast retains only the newest N claims of the owning workload.
+	KeepLast string
+	// TTLAfterPodDeletion keeps the claim this long after its pod is gone.
+	TTLAfterPodDeletion string
+	// NFSServer and NFSPath request a static NFS-backed PV/PVC pair.
+	NFSServer string
+	NFSPath   string
+	// StorageClassParams requests a derived StorageClass with overrides.
+	StorageClassParams string
+	// Template names a PVCTemplate whose spec the claim starts from.
+	Template string
+}
+
+// ParseVolumeParams reads every parameter the pod sets for the named volume,
+// honoring the legacy single-volume keys the canonical schema still accepts.
+func ParseVolumeParams(pod *corev1.Pod, volName string) VolumeParams {
+	get := func(param string) string {
+		return schema.Get(pod.Annotations, volName, param)
+	}
+	return VolumeParams{
+		Volume:                volName,
+		Claim:                 get(schema.Claim),
+		Size:                  get(schema.Size),
+		StorageClass:          get(schema.StorageClass),
+		AccessModes:           splitList(get(schema.AccessModes)),
+		Profile:               get(schema.Profile),
+		Identity:              get(schema.Identity),
+		Shared:                get(schema.Shared) == "true",
+		TopologyPin:           get(schema.TopologyPin) == "true",
+		CSIParams:             get(schema.CSIParams),
+		DataSource:            get(schema.DataSource),
+		DataSourceNamespace:   get(schema.DataSourceNamespace),
+		FromSnapshot:          get(schema.FromSnapshot),
+		VolumeName:            get(schema.VolumeName),
+		Selector:              get(schema.Selector),
+		VolumeMode:            get(schema.VolumeMode),
+		ReclaimPolicy:         get(schema.ReclaimPolicy),
+		VolumeAttributesClass: get(schema.VolumeAttributesClass),
+		KeepLast:              get(schema.KeepLast),
+		TTLAfterPodDeletion:   get(schema.TTLAfterPodDeletion),
+		NFSServer:             get(schema.NFSServer),
+		NFSPath:               get(schema.NFSPath),
+		StorageClassParams:    get(schema.StorageClassParams),
+		Template:              get(schema.Template),
+	}
+}
+
+// Validate returns a descriptive error for the first parameter that does not
+// parse, or nil when every set parameter is well-formed. It applies the same
+// rules as the webhook's strict mode, so tooling can reject bad annotations
+// before a pod ever reaches the cluster.
+func (p VolumeParams) Validate() error {
+	if p.Size != "" {
+		if _, err := schema.ParseSize(p.Size); err != nil {
+			return fmt.Errorf("volume %q: %v", p.Volume, err)
+		}
+	}
+	for _, m := range p.AccessModes {
+		if !ValidAccessMode(m) {
+			return fmt.Errorf("volume %q: unknown access mode %q", p.Volume, m)
+		}
+	}
+	if p.ReclaimPolicy != "" {
+		switch corev1.PersistentVolumeReclaimPolicy(p.ReclaimPolicy) {
+		case corev1.PersistentVolumeReclaimRetain, corev1.PersistentVolumeReclaimDelete:
+		default:
+			return fmt.Errorf("volume %q: unknown reclaim policy %q", p.Volume, p.ReclaimPolicy)
+		}
+	}
+	if p.KeepLast != "" {
+		n, err := strconv.Atoi(p.KeepLast)
+		if err != nil || n < 1 {
+			return fmt.Errorf("volume %q: invalid keepLast %q: must be a positive integer", p.Volume, p.KeepLast)
+		}
+	}
+	if p.TTLAfterPodDeletion != "" {
+		if _, err := time.ParseDuration(p.TTLAfterPodDeletion); err != nil {
+			return fmt.Errorf("volume %q: invalid ttlAfterPodDeletion %q: %v", p.Volume, p.TTLAfterPodDeletion, err)
+		}
+	}
+	if p.Selector != "" {
+		labels := map[string]string{}
+		if err := json.Unmarshal([]byte(p.Selector), &labels); err != nil {
+			return fmt.Errorf("volume %q: invalid selector %q: want a JSON object of labels", p.Volume, p.Selector)
+		}
+	}
+	if p.VolumeMode != "" {
+		switch corev1.PersistentVolumeMode(p.VolumeMode) {
+		case corev1.PersistentVolumeFilesystem, corev1.PersistentVolumeBlock:
+		default:
+			return fmt.Errorf("volume %q: unknown volumeMode %q, want Filesystem or Block", p.Volume, p.VolumeMode)
+		}
+	}
+	if p.DataSource != "" {
+		if parts := strings.Split(p.DataSource, "/"); len(parts) < 2 || len(parts) > 3 {
+			return fmt.Errorf("volume %q: invalid data-source %q: want [apiGroup/]Kind/name", p.Volume, p.DataSource)
+		}
+	}
+	if (p.NFSServer == "") != (p.NFSPath == "") {
+		return fmt.Errorf("volume %q: nfs.server and nfs.path must be set together", p.Volume)
+	}
+	return nil
+}
+
+// knownAccessModes are the access-mode spellings accepted in annotations.
+var knownAccessModes = map[string]bool{
+	"ReadWriteOnce":    true,
+	"RWO":              true,
+	"ReadOnlyMany":     true,
+	"ROX":              true,
+	"ReadWriteMany":    true,
+	"RWX":              true,
+	"ReadWriteOncePod": true,
+	"RWOP":             true,
+}
+
+// ValidAccessMode reports whether the spelling is an accepted access mode,
+// long or short form.
+func ValidAccessMode(mode string) bool {
+	return knownAccessModes[mode]
+}
+
+// splitList splits a comma-separated annotation value, trimming whitespace and
+// dropping empty entries.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
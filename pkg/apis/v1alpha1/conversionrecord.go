@@ -0,0 +1,81 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ConversionRecord is an audit trail entry: one is written per converted
+// volume when record keeping is enabled, capturing what was mutated, into
+// what, with which parameters, and on whose request. Records are immutable
+// and swept by the controller once they exceed the configured TTL.
+type ConversionRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ConversionRecordSpec `json:"spec"`
+}
+
+// ConversionRecordSpec describes one conversion.
+type ConversionRecordSpec struct {
+	// PodName and PodUID identify the mutated pod. PodUID is empty for pods
+	// admitted before the API server assigned one.
+	PodName string `json:"podName"`
+	PodUID  string `json:"podUID,omitempty"`
+	// Volume is the pod volume that was converted and VolumeSource its
+	// original source type (e.g. "emptyDir").
+	Volume       string `json:"volume"`
+	VolumeSource string `json:"volumeSource"`
+	// ClaimName is the PersistentVolumeClaim the volume now references.
+	ClaimName string `json:"claimName"`
+	// Parameters are the effective claim parameters at conversion time.
+	Parameters map[string]string `json:"parameters,omitempty"`
+	// RequestUID is the admission request UID, for correlation with the
+	// webhook's logs and the pod's admission-uid annotation.
+	RequestUID string `json:"requestUID,omitempty"`
+	// Username is the authenticated user who submitted the pod.
+	Username string `json:"username,omitempty"`
+	// WebhookVersion is the release that performed the mutation.
+	WebhookVersion string `json:"webhookVersion,omitempty"`
+	// ConvertedAt is when the mutation was admitted.
+	ConvertedAt metav1.Time `json:"convertedAt"`
+}
+
+// ConversionRecordList is a list of ConversionRecord objects.
+type ConversionRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ConversionRecord `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ConversionRecord) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ConversionRecord{TypeMeta: in.TypeMeta, Spec: in.Spec}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Parameters != nil {
+		out.Spec.Parameters = make(map[string]string, len(in.Spec.Parameters))
+		for k, v := range in.Spec.Parameters {
+			out.Spec.Parameters[k] = v
+		}
+	}
+	out.Spec.ConvertedAt = *in.Spec.ConvertedAt.DeepCopy()
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ConversionRecordList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ConversionRecordList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]ConversionRecord, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*ConversionRecord)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ManagedVolumeClaim records one emptyDir-to-PVC conversion. The controller
+// creates one per converted volume so operators can inspect conversions with
+// kubectl instead of decoding pod annotations.
+type ManagedVolumeClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedVolumeClaimSpec   `json:"spec"`
+	Status ManagedVolumeClaimStatus `json:"status,omitempty"`
+}
+
+// ManagedVolumeClaimSpec identifies the conversion source and parameters.
+type ManagedVolumeClaimSpec struct {
+	// PodName is the pod whose volume was converted.
+	PodName string `json:"podName"`
+	// PodUID pins the record to one pod incarnation.
+	PodUID string `json:"podUID,omitempty"`
+	// Volume is the name of the converted emptyDir volume.
+	Volume string `json:"volume"`
+	// Parameters are the per-volume annotation parameters in effect at
+	// conversion time (size, storage-class, ...).
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ManagedVolumeClaimStatus reports the state of the backing claim.
+type ManagedVolumeClaimStatus struct {
+	// ClaimName is the PersistentVolumeClaim created for the volume.
+	ClaimName string `json:"claimName,omitempty"`
+	// Phase mirrors the claim's phase (Pending, Bound, Lost), or NotFound.
+	Phase string `json:"phase,omitempty"`
+	// BoundTime is when the claim was first observed Bound.
+	BoundTime *metav1.Time `json:"boundTime,omitempty"`
+	// Message carries the most recent provisioning error, if any.
+	Message string `json:"message,omitempty"`
+	// Conditions follow the standard condition conventions; the Ready
+	// condition is true once the claim is Bound.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ManagedVolumeClaimList is a list of ManagedVolumeClaim objects.
+type ManagedVolumeClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ManagedVolumeClaim `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ManagedVolumeClaim) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ManagedVolumeClaim{TypeMeta: in.TypeMeta}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.PodName = in.Spec.PodName
+	out.Spec.PodUID = in.Spec.PodUID
+	out.Spec.Volume = in.Spec.Volume
+	if in.Spec.Parameters != nil {
+		out.Spec.Parameters = make(map[string]string, len(in.Spec.Parameters))
+		for k, v := range in.Spec.Parameters {
+			out.Spec.Parameters[k] = v
+		}
+	}
+	out.Status.ClaimName = in.Status.ClaimName
+	out.Status.Phase = in.Status.Phase
+	out.Status.Message = in.Status.Message
+	if in.Status.BoundTime != nil {
+		t := *in.Status.BoundTime
+		out.Status.BoundTime = &t
+	}
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]metav1.Condition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ManagedVolumeClaimList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ManagedVolumeClaimList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]ManagedVolumeClaim, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*ManagedVolumeClaim)
+	}
+	return out
+}
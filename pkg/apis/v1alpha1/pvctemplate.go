@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PVCTemplate is a reusable PersistentVolumeClaim spec that pods reference
+// with the per-volume template annotation, so complex specs (selectors,
+// dataSources, volume attributes) need not be squeezed into annotation
+// strings. Per-volume annotations still override individual parameters of
+// the template.
+type PVCTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PVCTemplateSpec `json:"spec"`
+}
+
+// PVCTemplateSpec carries the claim spec and metadata built from the
+// template.
+type PVCTemplateSpec struct {
+	// Labels and Annotations are merged onto claims built from the template.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Spec is the claim spec the created PVC starts from.
+	Spec corev1.PersistentVolumeClaimSpec `json:"spec"`
+}
+
+// PVCTemplateList is a list of PVCTemplate objects.
+type PVCTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PVCTemplate `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PVCTemplate) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &PVCTemplate{TypeMeta: in.TypeMeta}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spec.Labels != nil {
+		out.Spec.Labels = make(map[string]string, len(in.Spec.Labels))
+		for k, v := range in.Spec.Labels {
+			out.Spec.Labels[k] = v
+		}
+	}
+	if in.Spec.Annotations != nil {
+		out.Spec.Annotations = make(map[string]string, len(in.Spec.Annotations))
+		for k, v := range in.Spec.Annotations {
+			out.Spec.Annotations[k] = v
+		}
+	}
+	in.Spec.Spec.DeepCopyInto(&out.Spec.Spec)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *PVCTemplateList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &PVCTemplateList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]PVCTemplate, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*PVCTemplate)
+	}
+	return out
+}
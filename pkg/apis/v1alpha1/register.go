@@ -0,0 +1,38 @@
+// Package v1alpha1 contains the pvc-webhook.io API types.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// GroupName is the API group served by the pvc-webhook CRDs.
+const GroupName = "pvc-webhook.io"
+
+// SchemeGroupVersion is the group version used to register these objects.
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha1"}
+
+var (
+	// SchemeBuilder collects functions that add these types to a scheme.
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&StorageBudget{},
+		&StorageBudgetList{},
+		&ManagedVolumeClaim{},
+		&ManagedVolumeClaimList{},
+		&PVCTemplate{},
+		&PVCTemplateList{},
+		&StorageLimitRange{},
+		&StorageLimitRangeList{},
+		&ConversionRecord{},
+		&ConversionRecordList{},
+	)
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StorageBudget caps the storage footprint of webhook-created claims in its
+// namespace. The controller refuses or queues claim creations that would
+// exceed the budget and keeps status.used up to date.
+type StorageBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StorageBudgetSpec   `json:"spec"`
+	Status StorageBudgetStatus `json:"status,omitempty"`
+}
+
+// StorageBudgetSpec defines the limits enforced in the namespace.
+type StorageBudgetSpec struct {
+	// MaxTotalStorage is the total storage all managed claims in the
+	// namespace may request, e.g. "500Gi".
+	MaxTotalStorage resource.Quantity `json:"maxTotalStorage"`
+	// MaxClaims caps the number of managed claims; zero means unlimited.
+	MaxClaims int32 `json:"maxClaims,omitempty"`
+}
+
+// StorageBudgetStatus reports current consumption against the budget.
+type StorageBudgetStatus struct {
+	// UsedStorage is the summed storage request of existing managed claims.
+	UsedStorage resource.Quantity `json:"usedStorage,omitempty"`
+	// UsedClaims is the number of existing managed claims.
+	UsedClaims int32 `json:"usedClaims,omitempty"`
+}
+
+// StorageBudgetList is a list of StorageBudget objects.
+type StorageBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StorageBudget `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *StorageBudget) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &StorageBudget{TypeMeta: in.TypeMeta}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.MaxTotalStorage = in.Spec.MaxTotalStorage.DeepCopy()
+	out.Spec.MaxClaims = in.Spec.MaxClaims
+	out.Status.UsedStorage = in.Status.UsedStorage.DeepCopy()
+	out.Status.UsedClaims = in.Status.UsedClaims
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *StorageBudgetList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &StorageBudgetList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]StorageBudget, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*StorageBudget)
+	}
+	return out
+}
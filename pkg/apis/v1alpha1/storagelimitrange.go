@@ -0,0 +1,64 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StorageLimitRange bounds the size of claims the webhook creates or admits in
+// its namespace, analogous to a LimitRange for compute resources. Requests
+// outside the range are clamped into it rather than rejected, with a warning
+// surfaced to the client.
+type StorageLimitRange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec StorageLimitRangeSpec `json:"spec"`
+}
+
+// StorageLimitRangeSpec defines the bounds enforced in the namespace. All
+// fields are quantities like "1Gi"; empty fields leave the respective bound
+// unenforced.
+type StorageLimitRangeSpec struct {
+	// Min is the smallest storage request admitted; smaller requests are
+	// raised to it.
+	Min string `json:"min,omitempty"`
+	// Max is the largest storage request admitted; larger requests are
+	// lowered to it.
+	Max string `json:"max,omitempty"`
+	// Default is the storage request applied to converted volumes that set
+	// no size of their own, overriding the webhook's global default.
+	Default string `json:"default,omitempty"`
+}
+
+// StorageLimitRangeList is a list of StorageLimitRange objects.
+type StorageLimitRangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StorageLimitRange `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *StorageLimitRange) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &StorageLimitRange{TypeMeta: in.TypeMeta, Spec: in.Spec}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *StorageLimitRangeList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &StorageLimitRangeList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]StorageLimitRange, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*StorageLimitRange)
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+// Package v1beta1 contains the graduated pvc-webhook.io API types. It differs
+// from v1alpha1 in nesting the conversion source under spec.sourcePod; the
+// webhook's /convert endpoint translates stored objects between versions.
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+)
+
+// SchemeGroupVersion is the group version used to register these objects.
+var SchemeGroupVersion = schema.GroupVersion{Group: v1alpha1.GroupName, Version: "v1beta1"}
+
+var (
+	// SchemeBuilder collects functions that add these types to a scheme.
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&StorageBudget{},
+		&StorageBudgetList{},
+		&ManagedVolumeClaim{},
+		&ManagedVolumeClaimList{},
+	)
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
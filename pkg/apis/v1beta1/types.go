@@ -0,0 +1,166 @@
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// StorageBudget caps the storage footprint of webhook-created claims in its
+// namespace. The schema is unchanged from v1alpha1.
+type StorageBudget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StorageBudgetSpec   `json:"spec"`
+	Status StorageBudgetStatus `json:"status,omitempty"`
+}
+
+// StorageBudgetSpec defines the limits enforced in the namespace.
+type StorageBudgetSpec struct {
+	// MaxTotalStorage is the total storage all managed claims in the
+	// namespace may request, e.g. "500Gi".
+	MaxTotalStorage resource.Quantity `json:"maxTotalStorage"`
+	// MaxClaims caps the number of managed claims; zero means unlimited.
+	MaxClaims int32 `json:"maxClaims,omitempty"`
+}
+
+// StorageBudgetStatus reports current consumption against the budget.
+type StorageBudgetStatus struct {
+	// UsedStorage is the summed storage request of existing managed claims.
+	UsedStorage resource.Quantity `json:"usedStorage,omitempty"`
+	// UsedClaims is the number of existing managed claims.
+	UsedClaims int32 `json:"usedClaims,omitempty"`
+}
+
+// StorageBudgetList is a list of StorageBudget objects.
+type StorageBudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []StorageBudget `json:"items"`
+}
+
+// ManagedVolumeClaim records one emptyDir-to-PVC conversion. Unlike
+// v1alpha1, the source pod is a nested object so further identifying fields
+// can be added without more top-level spec keys.
+type ManagedVolumeClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ManagedVolumeClaimSpec   `json:"spec"`
+	Status ManagedVolumeClaimStatus `json:"status,omitempty"`
+}
+
+// ManagedVolumeClaimSpec identifies the conversion source and parameters.
+type ManagedVolumeClaimSpec struct {
+	// SourcePod identifies the pod whose volume was converted.
+	SourcePod SourcePod `json:"sourcePod"`
+	// Volume is the name of the converted emptyDir volume.
+	Volume string `json:"volume"`
+	// Parameters are the per-volume annotation parameters in effect at
+	// conversion time (size, storage-class, ...).
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// SourcePod identifies one pod incarnation.
+type SourcePod struct {
+	// Name is the pod's name.
+	Name string `json:"name"`
+	// UID pins the record to one pod incarnation.
+	UID string `json:"uid,omitempty"`
+}
+
+// ManagedVolumeClaimStatus reports the state of the backing claim.
+type ManagedVolumeClaimStatus struct {
+	// ClaimName is the PersistentVolumeClaim created for the volume.
+	ClaimName string `json:"claimName,omitempty"`
+	// Phase mirrors the claim's phase (Pending, Bound, Lost), or NotFound.
+	Phase string `json:"phase,omitempty"`
+	// BoundTime is when the claim was first observed Bound.
+	BoundTime *metav1.Time `json:"boundTime,omitempty"`
+	// Message carries the most recent provisioning error, if any.
+	Message string `json:"message,omitempty"`
+	// Conditions follow the standard condition conventions; the Ready
+	// condition is true once the claim is Bound.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ManagedVolumeClaimList is a list of ManagedVolumeClaim objects.
+type ManagedVolumeClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ManagedVolumeClaim `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *StorageBudget) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &StorageBudget{TypeMeta: in.TypeMeta}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.MaxTotalStorage = in.Spec.MaxTotalStorage.DeepCopy()
+	out.Spec.MaxClaims = in.Spec.MaxClaims
+	out.Status.UsedStorage = in.Status.UsedStorage.DeepCopy()
+	out.Status.UsedClaims = in.Status.UsedClaims
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *StorageBudgetList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &StorageBudgetList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]StorageBudget, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*StorageBudget)
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ManagedVolumeClaim) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ManagedVolumeClaim{TypeMeta: in.TypeMeta}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec.SourcePod = in.Spec.SourcePod
+	out.Spec.Volume = in.Spec.Volume
+	if in.Spec.Parameters != nil {
+		out.Spec.Parameters = make(map[string]string, len(in.Spec.Parameters))
+		for k, v := range in.Spec.Parameters {
+			out.Spec.Parameters[k] = v
+		}
+	}
+	out.Status.ClaimName = in.Status.ClaimName
+	out.Status.Phase = in.Status.Phase
+	out.Status.Message = in.Status.Message
+	if in.Status.BoundTime != nil {
+		t := *in.Status.BoundTime
+		out.Status.BoundTime = &t
+	}
+	if in.Status.Conditions != nil {
+		out.Status.Conditions = make([]metav1.Condition, len(in.Status.Conditions))
+		copy(out.Status.Conditions, in.Status.Conditions)
+	}
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *ManagedVolumeClaimList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := &ManagedVolumeClaimList{TypeMeta: in.TypeMeta}
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	out.Items = make([]ManagedVolumeClaim, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*ManagedVolumeClaim)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// AdoptionPolicy selects what happens when a claim with the derived name
+// already exists but was not created by this controller. Silently binding the
+// pod to such a claim risks wrong size or class; the policy makes the outcome
+// explicit.
+type AdoptionPolicy string
+
+const (
+	// AdoptionAdopt labels the claim as managed and reconciles it like any
+	// other; drift from the desired spec is then reported normally.
+	AdoptionAdopt AdoptionPolicy = "adopt"
+	// AdoptionReadOnly lets the pod use the claim but takes no ownership:
+	// the claim is never reconciled, expanded or garbage-collected.
+	AdoptionReadOnly AdoptionPolicy = "reuse-readonly"
+	// AdoptionError refuses the claim and posts a warning event on the pod.
+	AdoptionError AdoptionPolicy = "error"
+)
+
+// ValidAdoptionPolicy reports whether the value names a known policy; empty
+// means the default (adopt).
+func ValidAdoptionPolicy(value string) bool {
+	switch AdoptionPolicy(value) {
+	case "", AdoptionAdopt, AdoptionReadOnly, AdoptionError:
+		return true
+	}
+	return false
+}
+
+// adoptForeignClaim applies the adoption policy to a pre-existing claim that
+// lacks the managed-by label. It reports whether ensureClaim should continue
+// reconciling the claim; under the error policy it also returns the error.
+func (c *Controller) adoptForeignClaim(ctx context.Context, pod *corev1.Pod, volume string, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	switch c.cfg.AdoptionPolicy {
+	case AdoptionReadOnly:
+		klog.V(2).Infof("reusing pre-existing claim %s/%s for pod %s volume %s without ownership", pvc.Namespace, pvc.Name, pod.Name, volume)
+		return false, nil
+	case AdoptionError:
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "ClaimNotAdopted",
+			"PersistentVolumeClaim %q exists but was not created by pvc-webhook; delete or rename it, or relax the adoption policy", pvc.Name)
+		return false, fmt.Errorf("claim %s/%s exists but was not created by pvc-webhook and the adoption policy is %q", pvc.Namespace, pvc.Name, AdoptionError)
+	}
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:%q},"annotations":{%q:%q}}}`,
+		managedByLabelKey, managedByLabelValue, annotationSourceVolume, volume))
+	if _, err := c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return false, fmt.Errorf("adopting claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+	}
+	klog.Infof("adopted pre-existing claim %s/%s for pod %s volume %s", pvc.Namespace, pvc.Name, pod.Name, volume)
+	c.recorder.Eventf(pvc, corev1.EventTypeNormal, "ClaimAdopted",
+		"adopted pre-existing PersistentVolumeClaim for pod %s volume %s", pod.Name, volume)
+	return true, nil
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// The autoscaler periodically scrapes kubelet volume stats for managed claims
+// and expands those filling up past the configured threshold, so workloads do
+// not crash on full scratch volumes. Expansion relies on the storage class
+// allowing it; classes that do not simply fail the update, which is logged.
+
+// volumeUsage is the usage sample for one claim scraped from a kubelet.
+type volumeUsage struct {
+	usedBytes     float64
+	capacityBytes float64
+}
+
+// runAutoscaler expands nearly full managed claims on a fixed interval; a
+// zero interval disables the feature.
+func (c *Controller) runAutoscaler(ctx context.Context) {
+	if c.cfg.AutoscaleInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.AutoscaleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.autoscaleOnce(ctx)
+		}
+	}
+}
+
+// autoscaleOnce performs one scrape-and-expand pass over all managed claims.
+func (c *Controller) autoscaleOnce(ctx context.Context) {
+	threshold := c.cfg.AutoscaleThresholdPercent
+	if threshold <= 0 {
+		threshold = 80
+	}
+	stats := c.scrapeVolumeStats(ctx)
+	if len(stats) == 0 {
+		return
+	}
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("autoscaler: listing managed claims: %v", err)
+		return
+	}
+
+	var nsCap *resource.Quantity
+	if c.cfg.AutoscaleNamespaceCap != "" {
+		if q, err := resource.ParseQuantity(c.cfg.AutoscaleNamespaceCap); err != nil {
+			klog.Warningf("autoscaler: invalid namespace cap %q: %v", c.cfg.AutoscaleNamespaceCap, err)
+		} else {
+			nsCap = &q
+		}
+	}
+	// Total managed storage requested per namespace, to enforce the cap.
+	totals := map[string]int64{}
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		if req, ok := claim.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			totals[claim.Namespace] += req.Value()
+		}
+	}
+
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		usage, ok := stats[claim.Namespace+"/"+claim.Name]
+		if !ok || usage.capacityBytes == 0 {
+			continue
+		}
+		pct := int(usage.usedBytes / usage.capacityBytes * 100)
+		if pct < threshold {
+			continue
+		}
+		current := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		desired := *resource.NewQuantity(current.Value()*2, resource.BinarySI)
+		if nsCap != nil && totals[claim.Namespace]+current.Value() > nsCap.Value() {
+			klog.Warningf("autoscaler: not expanding %s/%s at %d%% usage: namespace cap %s reached",
+				claim.Namespace, claim.Name, pct, nsCap.String())
+			continue
+		}
+		claim.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+		if _, err := c.client.CoreV1().PersistentVolumeClaims(claim.Namespace).Update(ctx, claim, metav1.UpdateOptions{}); err != nil {
+			klog.Errorf("autoscaler: expanding %s/%s: %v", claim.Namespace, claim.Name, err)
+			continue
+		}
+		totals[claim.Namespace] += desired.Value() - current.Value()
+		klog.Infof("autoscaler: expanded %s/%s from %s to %s at %d%% usage",
+			claim.Namespace, claim.Name, current.String(), desired.String(), pct)
+		c.recorder.Eventf(claim, corev1.EventTypeNormal, "VolumeExpanded",
+			"expanded from %s to %s at %d%% usage", current.String(), desired.String(), pct)
+	}
+}
+
+// scrapeVolumeStats collects per-claim usage from every node's kubelet via
+// the API server node proxy, keyed by namespace/name. Nodes that fail to
+// scrape are skipped; a partial view only delays expansion by one interval.
+func (c *Controller) scrapeVolumeStats(ctx context.Context) map[string]volumeUsage {
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("autoscaler: listing nodes: %v", err)
+		return nil
+	}
+	stats := map[string]volumeUsage{}
+	for _, node := range nodes.Items {
+		raw, err := c.client.CoreV1().RESTClient().Get().
+			Resource("nodes").Name(node.Name).SubResource("proxy").Suffix("metrics").
+			DoRaw(ctx)
+		if err != nil {
+			klog.V(2).Infof("autoscaler: scraping node %s: %v", node.Name, err)
+			continue
+		}
+		parseVolumeStats(string(raw), stats)
+	}
+	return stats
+}
+
+// parseVolumeStats extracts kubelet_volume_stats_used_bytes and
+// kubelet_volume_stats_capacity_bytes samples from Prometheus text exposition
+// into the stats map.
+func parseVolumeStats(body string, stats map[string]volumeUsage) {
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		used := strings.HasPrefix(line, "kubelet_volume_stats_used_bytes{")
+		capacity := strings.HasPrefix(line, "kubelet_volume_stats_capacity_bytes{")
+		if !used && !capacity {
+			continue
+		}
+		namespace := metricLabel(line, "namespace")
+		claim := metricLabel(line, "persistentvolumeclaim")
+		if namespace == "" || claim == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		value, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil {
+			continue
+		}
+		key := namespace + "/" + claim
+		usage := stats[key]
+		if used {
+			usage.usedBytes = value
+		} else {
+			usage.capacityBytes = value
+		}
+		stats[key] = usage
+	}
+}
+
+// metricLabel extracts one label value from a Prometheus text sample line.
+func metricLabel(line, label string) string {
+	marker := label + `="`
+	i := strings.Index(line, marker)
+	if i < 0 {
+		return ""
+	}
+	rest := line[i+len(marker):]
+	j := strings.Index(rest, `"`)
+	if j < 0 {
+		return ""
+	}
+	return rest[:j]
+}
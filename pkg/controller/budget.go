@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+)
+
+// storageBudgetGVR locates StorageBudget objects via the dynamic client.
+var storageBudgetGVR = schema.GroupVersionResource{
+	Group:    v1alpha1.GroupName,
+	Version:  "v1alpha1",
+	Resource: "storagebudgets",
+}
+
+// SetDynamicClient enables CRD-backed features such as StorageBudget
+// enforcement. Without it those features are skipped.
+func (c *Controller) SetDynamicClient(client dynamic.Interface) {
+	c.dynamic = client
+}
+
+// budgetAllows reports whether creating a claim of the given size fits the
+// namespace's StorageBudget, if one exists. Denials are reported as events on
+// the pod and the creation is queued for retry, since budget may free up.
+func (c *Controller) budgetAllows(ctx context.Context, pod *corev1.Pod, claim string, size resource.Quantity) (bool, error) {
+	budget, err := c.namespaceBudget(ctx, pod.Namespace)
+	if err != nil || budget == nil {
+		return err == nil, err
+	}
+
+	used, count, err := c.managedUsage(ctx, pod.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	if budget.Spec.MaxClaims > 0 && count+1 > budget.Spec.MaxClaims {
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "StorageBudgetExceeded",
+			"claim %q would exceed the namespace budget of %d claims", claim, budget.Spec.MaxClaims)
+		return false, nil
+	}
+	wanted := used.DeepCopy()
+	wanted.Add(size)
+	if wanted.Cmp(budget.Spec.MaxTotalStorage) > 0 {
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "StorageBudgetExceeded",
+			"claim %q (%s) would exceed the namespace storage budget of %s (used: %s)",
+			claim, size.String(), budget.Spec.MaxTotalStorage.String(), used.String())
+		return false, nil
+	}
+	return true, nil
+}
+
+// namespaceBudget returns the namespace's StorageBudget, or nil if none is
+// defined or CRD support is not wired up.
+func (c *Controller) namespaceBudget(ctx context.Context, namespace string) (*v1alpha1.StorageBudget, error) {
+	if c.dynamic == nil {
+		return nil, nil
+	}
+	list, err := c.dynamic.Resource(storageBudgetGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		// The CRD may not be installed; budgets are then simply not
+		// enforced rather than blocking all claim creation.
+		klog.V(2).Infof("listing storage budgets in %s: %v", namespace, err)
+		return nil, nil
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	budget := &v1alpha1.StorageBudget{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, budget); err != nil {
+		return nil, fmt.Errorf("decoding storage budget: %v", err)
+	}
+	return budget, nil
+}
+
+// managedUsage sums storage requests and counts of managed claims in the
+// namespace.
+func (c *Controller) managedUsage(ctx context.Context, namespace string) (resource.Quantity, int32, error) {
+	var used resource.Quantity
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		return used, 0, err
+	}
+	for i := range claims.Items {
+		if req, ok := claims.Items[i].Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			used.Add(req)
+		}
+	}
+	return used, int32(len(claims.Items)), nil
+}
+
+// updateBudgetStatus refreshes status.used on the namespace's budget after a
+// claim is created or deleted.
+func (c *Controller) updateBudgetStatus(ctx context.Context, namespace string) {
+	budget, err := c.namespaceBudget(ctx, namespace)
+	if err != nil || budget == nil {
+		return
+	}
+	used, count, err := c.managedUsage(ctx, namespace)
+	if err != nil {
+		klog.Errorf("computing budget usage in %s: %v", namespace, err)
+		return
+	}
+	budget.Status.UsedStorage = used
+	budget.Status.UsedClaims = count
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(budget)
+	if err != nil {
+		klog.Errorf("encoding storage budget: %v", err)
+		return
+	}
+	if _, err := c.dynamic.Resource(storageBudgetGVR).Namespace(namespace).UpdateStatus(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{}); err != nil {
+		klog.V(2).Infof("updating storage budget status in %s: %v", namespace, err)
+	}
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// runStorageClassWatch watches StorageClasses and raises the alarm when one
+// backing managed claims is deleted: existing volumes keep working, but new
+// pods of the same workloads will fail to provision, which operators should
+// hear about before it happens.
+func (c *Controller) runStorageClassWatch(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		w, err := c.client.StorageV1().StorageClasses().Watch(ctx, metav1.ListOptions{})
+		if err != nil {
+			klog.Errorf("watching storage classes: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		c.consumeStorageClasses(ctx, w)
+	}
+}
+
+func (c *Controller) consumeStorageClasses(ctx context.Context, w watch.Interface) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			if ev.Type != watch.Deleted {
+				continue
+			}
+			if sc, ok := ev.Object.(*storagev1.StorageClass); ok {
+				c.reportDeletedStorageClass(ctx, sc.Name)
+			}
+		}
+	}
+}
+
+// reportDeletedStorageClass enumerates the managed claims still referencing a
+// deleted StorageClass and emits a warning event per claim plus a gauge of
+// the affected count.
+func (c *Controller) reportDeletedStorageClass(ctx context.Context, class string) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("listing managed claims after deletion of storage class %q: %v", class, err)
+		return
+	}
+	affected := 0
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != class {
+			continue
+		}
+		affected++
+		klog.Warningf("storage class %q was deleted but managed claim %s/%s still references it", class, pvc.Namespace, pvc.Name)
+		c.recorder.Eventf(pvc, corev1.EventTypeWarning, "StorageClassDeleted",
+			"StorageClass %q was deleted; new claims of this workload will fail to provision", class)
+	}
+	metrics.DeletedStorageClassClaims.WithLabelValues(class).Set(float64(affected))
+	if affected > 0 {
+		klog.Warningf("storage class %q deleted with %d managed claim(s) still referencing it", class, affected)
+	}
+}
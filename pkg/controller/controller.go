@@ -0,0 +1,279 @@
+// Package controller watches pods mutated by the webhook and creates the
+// PersistentVolumeClaims their rewritten volumes reference.
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// OrphanAction selects what the sweeper does with orphaned claims.
+type OrphanAction string
+
+const (
+	// OrphanDelete removes orphaned claims after the grace period.
+	OrphanDelete OrphanAction = "delete"
+	// OrphanFlag only annotates orphaned claims for operators to review.
+	OrphanFlag OrphanAction = "flag"
+)
+
+// Config carries the defaults applied when pod annotations omit parameters.
+type Config struct {
+	// DefaultSize is the storage request used when no size annotation is
+	// present, e.g. "2Gi".
+	DefaultSize string
+	// DefaultStorageClass is used when no storage-class annotation is
+	// present. Empty means the cluster default.
+	DefaultStorageClass string
+	// ClassDefaultSizes overrides DefaultSize per storage class, e.g.
+	// {"fast-ssd": "20Gi", "standard": "5Gi"}.
+	ClassDefaultSizes map[string]string
+	// OrphanSweepInterval is how often the orphan sweeper runs; zero
+	// disables it.
+	OrphanSweepInterval time.Duration
+	// OrphanGracePeriod is how long a claim must stay orphaned before the
+	// sweeper acts on it.
+	OrphanGracePeriod time.Duration
+	// OrphanAction is what the sweeper does with orphans (delete or flag).
+	OrphanAction OrphanAction
+	// ClaimCreatesPerSecond rate-limits claim creation per namespace; zero
+	// means unlimited.
+	ClaimCreatesPerSecond float64
+	// ClaimCreateBurst is the per-namespace burst allowance.
+	ClaimCreateBurst int
+	// ProvisioningDeadline is how long a managed claim may stay Pending or
+	// Lost before the timeout action runs; zero disables the check.
+	ProvisioningDeadline time.Duration
+	// ProvisioningTimeoutAction is what happens to pods whose claims miss
+	// the deadline (warn, annotate, or delete).
+	ProvisioningTimeoutAction TimeoutAction
+	// DefaultSnapshotClass overrides per-driver VolumeSnapshotClass
+	// discovery for snapshot features; empty means discover.
+	DefaultSnapshotClass string
+	// BackupLabels and BackupAnnotations are stamped on every created
+	// claim so existing backup tooling (Velero, Kasten) picks it up.
+	BackupLabels      map[string]string
+	BackupAnnotations map[string]string
+	// DerivedStorageClasses lets volumes request per-claim storage class
+	// parameters; the controller creates and garbage-collects derived
+	// classes on demand.
+	DerivedStorageClasses bool
+	// EnableVolumeAttributesClasses gates use of the VolumeAttributesClass
+	// API; it is additionally subject to API discovery at runtime.
+	EnableVolumeAttributesClasses bool
+	// PreferReadWriteOncePod defaults converted volumes to the
+	// ReadWriteOncePod access mode on clusters that support it.
+	PreferReadWriteOncePod bool
+	// AutoscaleInterval is how often the autoscaler samples kubelet volume
+	// stats for managed claims; zero disables autoscaling.
+	AutoscaleInterval time.Duration
+	// AutoscaleThresholdPercent is the usage percentage above which a claim
+	// is expanded. Zero means the default of 80.
+	AutoscaleThresholdPercent int
+	// AutoscaleNamespaceCap bounds the total managed storage requested per
+	// namespace after expansion, e.g. "500Gi". Empty means no cap.
+	AutoscaleNamespaceCap string
+	// UsageMetricsInterval is how often kubelet volume stats for managed
+	// claims are republished as controller metrics; zero disables it.
+	UsageMetricsInterval time.Duration
+	// Profiles are the named storage profiles pods can request via the
+	// profile annotation.
+	Profiles map[string]Profile
+	// EnableCrossNamespaceDataSources gates data-source-namespace support;
+	// references are additionally subject to ReferenceGrant checks.
+	EnableCrossNamespaceDataSources bool
+	// PendingWarningThreshold is how long a managed claim may stay Pending
+	// before warning events are posted on its pods; zero disables events
+	// (the pending gauge is always exported).
+	PendingWarningThreshold time.Duration
+	// AdoptionPolicy is what happens when the derived claim name already
+	// exists but was not created by this controller (adopt, reuse-readonly,
+	// or error). Empty means adopt.
+	AdoptionPolicy AdoptionPolicy
+	// RecreateDeletedClaims recreates managed claims deleted out-of-band
+	// while a pod still references them.
+	RecreateDeletedClaims bool
+	// StuckTerminatingThreshold is how long a managed claim may sit in
+	// Terminating behind the pvc-protection finalizer before it is reported;
+	// zero disables the check.
+	StuckTerminatingThreshold time.Duration
+	// ForceReleaseStuckClaims removes the pvc-protection finalizer from
+	// stuck claims that no pod references anymore.
+	ForceReleaseStuckClaims bool
+	// ConversionRecordTTL is how long ConversionRecord audit objects written
+	// by the webhook are kept before the sweeper deletes them; zero disables
+	// the cleanup.
+	ConversionRecordTTL time.Duration
+}
+
+// Controller creates PVCs for webhook-converted pod volumes.
+type Controller struct {
+	client   kubernetes.Interface
+	dynamic  dynamic.Interface
+	cfg      Config
+	recorder record.EventRecorder
+	limiter  *namespaceLimiter
+
+	pendingMu sync.Mutex
+	pending   []deferredClaim
+
+	snapClasses snapshotClassCache
+
+	provisionerMu sync.Mutex
+	provisioners  map[string]string
+
+	boundSeenMu sync.Mutex
+	boundSeen   map[types.UID]bool
+
+	watchAliveMu sync.Mutex
+	watchAlive   time.Time
+
+	pauseMu    sync.Mutex
+	pauseCache map[string]pauseEntry
+}
+
+// New returns a Controller using the given clientset.
+func New(client kubernetes.Interface, cfg Config) *Controller {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return &Controller{
+		client:   client,
+		cfg:      cfg,
+		recorder: broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "pvc-webhook-controller"}),
+		limiter:  newNamespaceLimiter(cfg.ClaimCreatesPerSecond, cfg.ClaimCreateBurst),
+	}
+}
+
+// Run watches pods until the context is cancelled. The watch resumes from the
+// last observed resourceVersion when the server closes it, relists when that
+// version has expired from etcd, and backs off with jitter on repeated errors
+// instead of spinning.
+func (c *Controller) Run(ctx context.Context) {
+	go c.runJobWatch(ctx)
+	go c.runSweeper(ctx)
+	go c.runPendingRetries(ctx)
+	go c.runClaimWatch(ctx)
+	go c.runDeadlineChecker(ctx)
+	go c.runAutoscaler(ctx)
+	go c.runUsageExporter(ctx)
+	go c.runStorageClassWatch(ctx)
+	go c.runPendingMonitor(ctx)
+	c.markWatchAlive()
+	backoff := newWatchBackoff()
+	rv := ""
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if rv == "" {
+			list, err := c.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				klog.Errorf("listing pods to start watch: %v", err)
+				backoff.sleep(ctx)
+				continue
+			}
+			for i := range list.Items {
+				c.syncPod(ctx, &list.Items[i])
+			}
+			rv = list.ResourceVersion
+			c.markWatchAlive()
+		}
+		w, err := c.client.CoreV1().Pods(metav1.NamespaceAll).Watch(ctx, metav1.ListOptions{
+			ResourceVersion:     rv,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			if errors.IsResourceExpired(err) || errors.IsGone(err) {
+				klog.Warningf("pod watch resourceVersion %s expired, relisting", rv)
+				rv = ""
+				continue
+			}
+			klog.Errorf("watching pods: %v", err)
+			backoff.sleep(ctx)
+			continue
+		}
+		backoff.reset()
+		rv = c.consume(ctx, w, rv)
+	}
+}
+
+// consume drains a pod watch, returning the resourceVersion to resume from
+// when the watch terminates. An empty return means the version expired and the
+// caller must relist.
+func (c *Controller) consume(ctx context.Context, w watch.Interface, rv string) string {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return rv
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return rv
+			}
+			c.markWatchAlive()
+			if ev.Type == watch.Error {
+				err := errors.FromObject(ev.Object)
+				if errors.IsResourceExpired(err) || errors.IsGone(err) {
+					klog.Warningf("pod watch resourceVersion %s expired, relisting", rv)
+					return ""
+				}
+				klog.Errorf("pod watch error event: %v", err)
+				return rv
+			}
+			pod, ok := ev.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+			rv = pod.ResourceVersion
+			switch ev.Type {
+			case watch.Added, watch.Modified:
+				c.syncPod(ctx, pod)
+			case watch.Deleted:
+				c.handlePodDeleted(ctx, pod)
+			}
+		}
+	}
+}
+
+// syncPod ensures a claim exists for every converted volume recorded in the
+// pod's annotations. Terminating pods only get retention cleanup.
+func (c *Controller) syncPod(ctx context.Context, pod *corev1.Pod) {
+	if pod.DeletionTimestamp != nil {
+		c.finalizeRetainedPod(ctx, pod)
+		return
+	}
+	if c.namespacePaused(ctx, pod.Namespace) {
+		klog.V(2).Infof("namespace %q is paused, skipping pod %s", pod.Namespace, pod.Name)
+		return
+	}
+	if c.namespaceTerminating(ctx, pod.Namespace) {
+		klog.V(2).Infof("namespace %q is terminating, skipping pod %s", pod.Namespace, pod.Name)
+		return
+	}
+	c.ensureRetentionFinalizer(ctx, pod)
+	for volume, claim := range annotations.Claims(pod.Annotations) {
+		if err := c.ensureClaim(ctx, pod, volume, claim); err != nil {
+			klog.Errorf("ensuring claim %s/%s for pod %s: %v", pod.Namespace, claim, pod.Name, err)
+		}
+		c.pinClaimTopology(ctx, pod, volume, claim)
+		c.reconcileVolumeAttributes(ctx, pod, volume, claim)
+		c.ensureManagedVolumeClaim(ctx, pod, volume, claim)
+	}
+	c.reportStatus(ctx, pod)
+	c.maybeLiftSchedulingGate(ctx, pod)
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Cross-namespace data sources let golden datasets maintained in a central
+// namespace seed converted volumes. The Kubernetes CrossNamespaceVolumeDataSource
+// feature requires a ReferenceGrant in the source namespace permitting the
+// reference, so the controller verifies one exists before pointing the claim
+// across the boundary.
+
+// referenceGrantGVR locates ReferenceGrant objects via the dynamic client.
+var referenceGrantGVR = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1beta1",
+	Resource: "referencegrants",
+}
+
+// applyCrossNamespaceDataSource points the claim's data source at the
+// namespace named by the data-source-namespace annotation, after verifying a
+// ReferenceGrant permits it. It is a no-op unless the feature gate is on.
+func (c *Controller) applyCrossNamespaceDataSource(ctx context.Context, pod *corev1.Pod, volume string, pvc *corev1.PersistentVolumeClaim) error {
+	srcNS := annotations.Get(pod.Annotations, volume, annotations.DataSourceNamespace)
+	if srcNS == "" || srcNS == pod.Namespace {
+		return nil
+	}
+	if !c.cfg.EnableCrossNamespaceDataSources {
+		klog.Warningf("ignoring data-source-namespace %q for pod %s/%s volume %s: cross-namespace data sources are disabled", srcNS, pod.Namespace, pod.Name, volume)
+		return nil
+	}
+	if pvc.Spec.DataSourceRef == nil {
+		klog.Warningf("ignoring data-source-namespace %q for pod %s/%s volume %s: no data-source or fromSnapshot set", srcNS, pod.Namespace, pod.Name, volume)
+		return nil
+	}
+	if c.dynamic == nil {
+		klog.Warningf("ignoring data-source-namespace %q for pod %s/%s volume %s: dynamic client unavailable for ReferenceGrant checks", srcNS, pod.Namespace, pod.Name, volume)
+		return nil
+	}
+
+	granted, err := c.referenceGrantAllows(ctx, srcNS, pod.Namespace, pvc.Spec.DataSourceRef)
+	if err != nil {
+		return fmt.Errorf("checking ReferenceGrants in %q: %v", srcNS, err)
+	}
+	if !granted {
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "DataSourceDenied",
+			"no ReferenceGrant in namespace %q permits claims in %q to reference %s %q",
+			srcNS, pod.Namespace, pvc.Spec.DataSourceRef.Kind, pvc.Spec.DataSourceRef.Name)
+		return fmt.Errorf("no ReferenceGrant in namespace %q permits the data source reference", srcNS)
+	}
+	pvc.Spec.DataSourceRef.Namespace = &srcNS
+	return nil
+}
+
+// referenceGrantAllows reports whether any ReferenceGrant in the source
+// namespace allows PersistentVolumeClaims from the claim namespace to
+// reference the data source's group and kind.
+func (c *Controller) referenceGrantAllows(ctx context.Context, srcNS, claimNS string, ref *corev1.TypedObjectReference) (bool, error) {
+	grants, err := c.dynamic.Resource(referenceGrantGVR).Namespace(srcNS).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	refGroup := ""
+	if ref.APIGroup != nil {
+		refGroup = *ref.APIGroup
+	}
+	for _, grant := range grants.Items {
+		if grantMatches(&grant, claimNS, refGroup, ref.Kind) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// grantMatches reports whether one ReferenceGrant permits PersistentVolumeClaims
+// in fromNS to reference objects of the given group and kind.
+func grantMatches(grant *unstructured.Unstructured, fromNS, toGroup, toKind string) bool {
+	froms, _, _ := unstructured.NestedSlice(grant.Object, "spec", "from")
+	fromOK := false
+	for _, f := range froms {
+		entry, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(entry, "group")
+		kind, _, _ := unstructured.NestedString(entry, "kind")
+		namespace, _, _ := unstructured.NestedString(entry, "namespace")
+		if group == "" && kind == "PersistentVolumeClaim" && namespace == fromNS {
+			fromOK = true
+			break
+		}
+	}
+	if !fromOK {
+		return false
+	}
+	tos, _, _ := unstructured.NestedSlice(grant.Object, "spec", "to")
+	for _, t := range tos {
+		entry, ok := t.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(entry, "group")
+		kind, _, _ := unstructured.NestedString(entry, "kind")
+		if group == toGroup && kind == toKind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// TimeoutAction selects how the controller reacts when a managed claim stays
+// unprovisioned past the deadline.
+type TimeoutAction string
+
+const (
+	// TimeoutWarn only emits a warning event on the affected pods.
+	TimeoutWarn TimeoutAction = "warn"
+	// TimeoutAnnotate additionally marks the pods with a timeout annotation.
+	TimeoutAnnotate TimeoutAction = "annotate"
+	// TimeoutDelete deletes the affected pods so their owning workloads
+	// reschedule them instead of hanging in ContainerCreating.
+	TimeoutDelete TimeoutAction = "delete"
+)
+
+// annotationProvisioningTimeout marks pods whose claim missed the deadline.
+const annotationProvisioningTimeout = "pvc-webhook/provisioning-timeout"
+
+// runDeadlineChecker periodically flags managed claims that stayed Pending or
+// Lost beyond the configured provisioning deadline.
+func (c *Controller) runDeadlineChecker(ctx context.Context) {
+	if c.cfg.ProvisioningDeadline <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkProvisioningDeadlines(ctx)
+		}
+	}
+}
+
+func (c *Controller) checkProvisioningDeadlines(ctx context.Context) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("deadline checker: listing claims: %v", err)
+		return
+	}
+	now := time.Now()
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if pvc.Status.Phase != corev1.ClaimPending && pvc.Status.Phase != corev1.ClaimLost {
+			continue
+		}
+		if now.Sub(pvc.CreationTimestamp.Time) < c.cfg.ProvisioningDeadline {
+			continue
+		}
+		c.handleProvisioningTimeout(ctx, pvc)
+	}
+}
+
+// handleProvisioningTimeout acts on every pod mounting the overdue claim
+// according to the configured action.
+func (c *Controller) handleProvisioningTimeout(ctx context.Context, pvc *corev1.PersistentVolumeClaim) {
+	pods, err := c.client.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("deadline checker: listing pods for claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !podMountsClaim(pod, pvc.Name) {
+			continue
+		}
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "ProvisioningTimeout",
+			"PersistentVolumeClaim %q has been %s for more than %s", pvc.Name, pvc.Status.Phase, c.cfg.ProvisioningDeadline)
+
+		switch c.cfg.ProvisioningTimeoutAction {
+		case TimeoutAnnotate:
+			patch, _ := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{annotationProvisioningTimeout: pvc.Name},
+				},
+			})
+			if _, err := c.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				klog.Errorf("deadline checker: annotating pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+		case TimeoutDelete:
+			if err := c.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+				klog.Errorf("deadline checker: deleting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				continue
+			}
+			klog.Infof("deadline checker: deleted pod %s/%s, claim %s overdue", pod.Namespace, pod.Name, pvc.Name)
+		}
+	}
+}
+
+// podMountsClaim reports whether any pod volume references the claim.
+func podMountsClaim(pod *corev1.Pod, claim string) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == claim {
+			return true
+		}
+	}
+	return false
+}
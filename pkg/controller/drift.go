@@ -0,0 +1,73 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// reconcileClaimDrift compares an existing claim against what the pod's
+// annotations currently demand. Label and annotation drift is corrected by the
+// subsequent server-side apply; size increases ride along as an expansion.
+// Immutable mismatches (storage class, access modes) and size decreases cannot
+// be reconciled, so they are reported as SpecDrift events and the desired spec
+// is clamped to the existing value to keep the apply valid.
+func (c *Controller) reconcileClaimDrift(pod *corev1.Pod, volume string, existing, desired *corev1.PersistentVolumeClaim) {
+	if sc := className(existing); sc != className(desired) {
+		c.recordSpecDrift(existing, "storage class is %q but pod %s/%s volume %q requests %q; storage class is immutable",
+			sc, pod.Namespace, pod.Name, volume, className(desired))
+		desired.Spec.StorageClassName = existing.Spec.StorageClassName
+	}
+	if !accessModesEqual(existing.Spec.AccessModes, desired.Spec.AccessModes) {
+		c.recordSpecDrift(existing, "access modes are %v but pod %s/%s volume %q requests %v; access modes are immutable",
+			existing.Spec.AccessModes, pod.Namespace, pod.Name, volume, desired.Spec.AccessModes)
+		desired.Spec.AccessModes = existing.Spec.AccessModes
+	}
+
+	have := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	want := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch want.Cmp(have) {
+	case 1:
+		klog.Infof("expanding claim %s/%s from %s to %s for pod %s volume %s",
+			existing.Namespace, existing.Name, have.String(), want.String(), pod.Name, volume)
+	case -1:
+		c.recordSpecDrift(existing, "requested size is %s but pod %s/%s volume %q requests %s; claims cannot shrink",
+			have.String(), pod.Namespace, pod.Name, volume, want.String())
+		desired.Spec.Resources.Requests[corev1.ResourceStorage] = have
+	}
+}
+
+// recordSpecDrift logs and emits a SpecDrift warning event on the claim.
+func (c *Controller) recordSpecDrift(pvc *corev1.PersistentVolumeClaim, format string, args ...interface{}) {
+	klog.Warningf("spec drift on claim %s/%s: "+format, append([]interface{}{pvc.Namespace, pvc.Name}, args...)...)
+	if c.recorder != nil {
+		c.recorder.Eventf(pvc, corev1.EventTypeWarning, "SpecDrift", format, args...)
+	}
+}
+
+// className returns the claim's storage class, "" meaning the cluster default.
+func className(pvc *corev1.PersistentVolumeClaim) string {
+	if pvc.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *pvc.Spec.StorageClassName
+}
+
+// accessModesEqual compares access mode sets independent of order.
+func accessModesEqual(a, b []corev1.PersistentVolumeAccessMode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, m := range a {
+		found := false
+		for _, n := range b {
+			if m == n {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
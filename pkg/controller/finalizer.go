@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Retain-mode cleanup: claims that outlive their pod (a Retain reclaim policy
+// or a ttlAfterPodDeletion window) need bookkeeping done before the pod
+// object disappears — the deletion time stamped and the pod's owner reference
+// dropped so garbage collection cannot race the retention policy. A finalizer
+// on the pod guarantees the controller gets that chance, instead of relying
+// on best-effort observation of delete events that a restart can miss.
+
+// podRetentionFinalizer marks pods whose claims need cleanup before the pod
+// is allowed to go away.
+const podRetentionFinalizer = "pvc-webhook.vol/retention"
+
+// labelRetained is stamped on claims released from their pod's ownership, so
+// operators can list what retention has kept around.
+const labelRetained = "pvc-webhook/retained"
+
+// podNeedsRetentionFinalizer reports whether any converted volume of the pod
+// keeps its claim past pod deletion.
+func podNeedsRetentionFinalizer(pod *corev1.Pod) bool {
+	for volume := range annotations.Claims(pod.Annotations) {
+		if annotations.Get(pod.Annotations, volume, annotations.TTLAfterPodDeletion) != "" {
+			return true
+		}
+		if annotations.Get(pod.Annotations, volume, annotations.ReclaimPolicy) == string(corev1.PersistentVolumeReclaimRetain) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFinalizer(finalizers []string, name string) bool {
+	for _, f := range finalizers {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureRetentionFinalizer adds the retention finalizer to live pods whose
+// claims outlive them.
+func (c *Controller) ensureRetentionFinalizer(ctx context.Context, pod *corev1.Pod) {
+	if !podNeedsRetentionFinalizer(pod) || hasFinalizer(pod.Finalizers, podRetentionFinalizer) {
+		return
+	}
+	var patch []byte
+	if len(pod.Finalizers) == 0 {
+		patch = []byte(fmt.Sprintf(`[{"op":"add","path":"/metadata/finalizers","value":[%q]}]`, podRetentionFinalizer))
+	} else {
+		patch = []byte(fmt.Sprintf(`[{"op":"add","path":"/metadata/finalizers/-","value":%q}]`, podRetentionFinalizer))
+	}
+	if _, err := c.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("adding retention finalizer to pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	klog.Infof("added retention finalizer to pod %s/%s", pod.Namespace, pod.Name)
+}
+
+// finalizeRetainedPod runs retention bookkeeping for a terminating pod and
+// then removes the finalizer so deletion can proceed. Stamping the deletion
+// time here duplicates what the delete-event handler does, but the finalizer
+// path is the one that cannot be missed; both are idempotent.
+func (c *Controller) finalizeRetainedPod(ctx context.Context, pod *corev1.Pod) {
+	if !hasFinalizer(pod.Finalizers, podRetentionFinalizer) {
+		return
+	}
+	c.handlePodDeleted(ctx, pod)
+	for _, claim := range annotations.Claims(pod.Annotations) {
+		c.releaseClaimFromPod(ctx, pod, claim)
+	}
+	c.removeRetentionFinalizer(ctx, pod)
+}
+
+// releaseClaimFromPod drops the terminating pod's owner reference from the
+// claim and labels the claim as retained, so garbage collection leaves it to
+// the retention policy. Owner references to anything but the pod itself (a
+// Job, say) are kept.
+func (c *Controller) releaseClaimFromPod(ctx context.Context, pod *corev1.Pod, claim string) {
+	pvc, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("retention: getting claim %s/%s: %v", pod.Namespace, claim, err)
+		}
+		return
+	}
+	kept := pvc.OwnerReferences[:0]
+	for _, ref := range pvc.OwnerReferences {
+		if ref.UID != pod.UID {
+			kept = append(kept, ref)
+		}
+	}
+	if len(kept) == len(pvc.OwnerReferences) && pvc.Labels[labelRetained] == "true" {
+		return
+	}
+	refs, err := json.Marshal(kept)
+	if err != nil {
+		klog.Errorf("retention: encoding owner references of claim %s/%s: %v", pod.Namespace, claim, err)
+		return
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"ownerReferences":%s,"labels":{%q:"true"}}}`, refs, labelRetained))
+	if _, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Patch(ctx, claim, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("retention: releasing claim %s/%s from pod %s: %v", pod.Namespace, claim, pod.Name, err)
+		return
+	}
+	klog.Infof("retention: released claim %s/%s from terminating pod %s", pod.Namespace, claim, pod.Name)
+}
+
+// removeRetentionFinalizer takes the retention finalizer off the pod once
+// cleanup has run.
+func (c *Controller) removeRetentionFinalizer(ctx context.Context, pod *corev1.Pod) {
+	index := -1
+	for i, f := range pod.Finalizers {
+		if f == podRetentionFinalizer {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return
+	}
+	patch := []byte(fmt.Sprintf(`[{"op":"test","path":"/metadata/finalizers/%d","value":%q},{"op":"remove","path":"/metadata/finalizers/%d"}]`,
+		index, podRetentionFinalizer, index))
+	if _, err := c.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("removing retention finalizer from pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	klog.Infof("removed retention finalizer from pod %s/%s", pod.Namespace, pod.Name)
+}
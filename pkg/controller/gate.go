@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// schedulingGateName mirrors webhook.SchedulingGateName; the controller lifts
+// this gate once a pod's converted claims are ready.
+const schedulingGateName = "pvc-webhook.io/claims-bound"
+
+// maybeLiftSchedulingGate removes the webhook's scheduling gate once every
+// converted claim is Bound. Claims whose storage class binds on first
+// consumer are treated as ready immediately, since they cannot bind until the
+// pod schedules.
+func (c *Controller) maybeLiftSchedulingGate(ctx context.Context, pod *corev1.Pod) {
+	gateIndex := -1
+	for i, gate := range pod.Spec.SchedulingGates {
+		if gate.Name == schedulingGateName {
+			gateIndex = i
+			break
+		}
+	}
+	if gateIndex < 0 {
+		return
+	}
+
+	for _, claim := range annotations.Claims(pod.Annotations) {
+		ready, err := c.claimReady(ctx, pod.Namespace, claim)
+		if err != nil {
+			klog.Errorf("checking claim %s/%s for gated pod %s: %v", pod.Namespace, claim, pod.Name, err)
+			return
+		}
+		if !ready {
+			return
+		}
+	}
+
+	patch := []byte(fmt.Sprintf(`[{"op":"test","path":"/spec/schedulingGates/%d/name","value":%q},{"op":"remove","path":"/spec/schedulingGates/%d"}]`,
+		gateIndex, schedulingGateName, gateIndex))
+	if _, err := c.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.JSONPatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("lifting scheduling gate on pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	klog.Infof("lifted scheduling gate on pod %s/%s", pod.Namespace, pod.Name)
+}
+
+// claimReady reports whether a claim is Bound, or exists with a
+// WaitForFirstConsumer storage class (which can only bind after scheduling).
+func (c *Controller) claimReady(ctx context.Context, namespace, claim string) (bool, error) {
+	pvc, err := c.client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if pvc.Status.Phase == corev1.ClaimBound {
+		return true, nil
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	sc, err := c.client.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer, nil
+}
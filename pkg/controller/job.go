@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+// runJobWatch watches Jobs and deletes managed claims once their owning Job
+// finishes, so scratch volumes do not outlive completed Jobs. Claims removed
+// by TTL cleanup are covered by the Job owner reference instead.
+func (c *Controller) runJobWatch(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		w, err := c.client.BatchV1().Jobs(metav1.NamespaceAll).Watch(ctx, metav1.ListOptions{})
+		if err != nil {
+			klog.Errorf("watching jobs: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		c.consumeJobs(ctx, w)
+	}
+}
+
+func (c *Controller) consumeJobs(ctx context.Context, w watch.Interface) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			if ev.Type != watch.Modified {
+				continue
+			}
+			job, ok := ev.Object.(*batchv1.Job)
+			if !ok || !jobFinished(job) {
+				continue
+			}
+			c.cleanupJobClaims(ctx, job)
+		}
+	}
+}
+
+// jobFinished reports whether the Job has a terminal Complete or Failed
+// condition.
+func jobFinished(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		if cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanupJobClaims deletes managed claims owned by the finished Job.
+func (c *Controller) cleanupJobClaims(ctx context.Context, job *batchv1.Job) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(job.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("listing claims for finished job %s/%s: %v", job.Namespace, job.Name, err)
+		return
+	}
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if !ownedBy(pvc.OwnerReferences, job.UID) {
+			continue
+		}
+		if err := c.client.CoreV1().PersistentVolumeClaims(job.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{}); err != nil {
+			klog.Errorf("deleting claim %s/%s for finished job %s: %v", job.Namespace, pvc.Name, job.Name, err)
+			continue
+		}
+		klog.Infof("deleted claim %s/%s: job %s finished", job.Namespace, pvc.Name, job.Name)
+	}
+}
+
+func ownedBy(refs []metav1.OwnerReference, uid types.UID) bool {
+	for _, ref := range refs {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
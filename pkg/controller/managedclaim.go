@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+)
+
+// managedVolumeClaimGVR locates ManagedVolumeClaim objects via the dynamic
+// client.
+var managedVolumeClaimGVR = schema.GroupVersionResource{
+	Group:    v1alpha1.GroupName,
+	Version:  "v1alpha1",
+	Resource: "managedvolumeclaims",
+}
+
+// ensureManagedVolumeClaim maintains the ManagedVolumeClaim record for a
+// converted volume: spec at creation, status (claim phase, bind time, Ready
+// condition) on every sync. Missing CRD support degrades to a debug log, like
+// the other CRD-backed features.
+func (c *Controller) ensureManagedVolumeClaim(ctx context.Context, pod *corev1.Pod, volume, claim string) {
+	if c.dynamic == nil {
+		return
+	}
+
+	mvc, err := c.getManagedVolumeClaim(ctx, pod.Namespace, claim)
+	if err != nil {
+		klog.V(2).Infof("getting managed volume claim %s/%s: %v", pod.Namespace, claim, err)
+		return
+	}
+	if mvc == nil {
+		mvc = &v1alpha1.ManagedVolumeClaim{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "ManagedVolumeClaim",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      claim,
+				Namespace: pod.Namespace,
+				Labels:    map[string]string{managedByLabelKey: managedByLabelValue},
+			},
+			Spec: v1alpha1.ManagedVolumeClaimSpec{
+				PodName:    pod.Name,
+				PodUID:     string(pod.UID),
+				Volume:     volume,
+				Parameters: volumeParameters(pod.Annotations, volume),
+			},
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mvc)
+		if err != nil {
+			klog.Errorf("encoding managed volume claim %s/%s: %v", pod.Namespace, claim, err)
+			return
+		}
+		created, err := c.dynamic.Resource(managedVolumeClaimGVR).Namespace(pod.Namespace).Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+		if err != nil {
+			if !errors.IsAlreadyExists(err) {
+				klog.V(2).Infof("creating managed volume claim %s/%s: %v", pod.Namespace, claim, err)
+			}
+			return
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(created.Object, mvc); err != nil {
+			klog.Errorf("decoding managed volume claim %s/%s: %v", pod.Namespace, claim, err)
+			return
+		}
+	}
+	c.updateManagedVolumeClaimStatus(ctx, mvc, claim)
+}
+
+// getManagedVolumeClaim fetches a record, returning nil without error when it
+// does not exist or the CRD is not installed.
+func (c *Controller) getManagedVolumeClaim(ctx context.Context, namespace, name string) (*v1alpha1.ManagedVolumeClaim, error) {
+	obj, err := c.dynamic.Resource(managedVolumeClaimGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	mvc := &v1alpha1.ManagedVolumeClaim{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, mvc); err != nil {
+		return nil, err
+	}
+	return mvc, nil
+}
+
+// updateManagedVolumeClaimStatus mirrors the backing claim's state onto the
+// record, skipping the write when nothing changed.
+func (c *Controller) updateManagedVolumeClaimStatus(ctx context.Context, mvc *v1alpha1.ManagedVolumeClaim, claim string) {
+	status := v1alpha1.ManagedVolumeClaimStatus{
+		ClaimName: claim,
+		Phase:     "NotFound",
+		BoundTime: mvc.Status.BoundTime,
+	}
+	pvc, err := c.client.CoreV1().PersistentVolumeClaims(mvc.Namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err == nil {
+		status.Phase = string(pvc.Status.Phase)
+	} else if !errors.IsNotFound(err) {
+		status.Message = err.Error()
+	}
+	if status.Phase == string(corev1.ClaimBound) && status.BoundTime == nil {
+		now := metav1.Now()
+		status.BoundTime = &now
+	}
+
+	ready := metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionFalse,
+		Reason:             "Claim" + status.Phase,
+		Message:            "claim " + claim + " is " + status.Phase,
+		LastTransitionTime: metav1.Now(),
+	}
+	if status.Phase == string(corev1.ClaimBound) {
+		ready.Status = metav1.ConditionTrue
+		ready.Reason = "ClaimBound"
+	}
+	// Preserve the transition time when the condition did not flip.
+	for _, cond := range mvc.Status.Conditions {
+		if cond.Type == ready.Type && cond.Status == ready.Status {
+			ready.LastTransitionTime = cond.LastTransitionTime
+		}
+	}
+	status.Conditions = []metav1.Condition{ready}
+
+	if statusEqual(mvc.Status, status) {
+		return
+	}
+	mvc.Status = status
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(mvc)
+	if err != nil {
+		klog.Errorf("encoding managed volume claim %s/%s: %v", mvc.Namespace, mvc.Name, err)
+		return
+	}
+	if _, err := c.dynamic.Resource(managedVolumeClaimGVR).Namespace(mvc.Namespace).UpdateStatus(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{}); err != nil {
+		klog.V(2).Infof("updating managed volume claim status %s/%s: %v", mvc.Namespace, mvc.Name, err)
+	}
+}
+
+// statusEqual compares the fields of two statuses that matter for skipping
+// no-op updates, ignoring condition timestamps.
+func statusEqual(a, b v1alpha1.ManagedVolumeClaimStatus) bool {
+	if a.ClaimName != b.ClaimName || a.Phase != b.Phase || a.Message != b.Message {
+		return false
+	}
+	if len(a.Conditions) != len(b.Conditions) {
+		return false
+	}
+	for i := range a.Conditions {
+		if a.Conditions[i].Type != b.Conditions[i].Type ||
+			a.Conditions[i].Status != b.Conditions[i].Status ||
+			a.Conditions[i].Reason != b.Conditions[i].Reason {
+			return false
+		}
+	}
+	return true
+}
+
+// volumeParameters extracts the per-volume annotation parameters for a
+// volume, keyed by bare parameter name.
+func volumeParameters(anns map[string]string, volume string) map[string]string {
+	prefix := annotations.Key(volume, "")
+	params := map[string]string{}
+	for key, value := range anns {
+		if strings.HasPrefix(key, prefix) {
+			params[strings.TrimPrefix(key, prefix)] = value
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
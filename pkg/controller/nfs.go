@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// nfsSource returns the static NFS backing requested for a volume, if any.
+// Both .nfs.server and .nfs.path must be set.
+func nfsSource(pod *corev1.Pod, volume string) (server, path string, ok bool) {
+	server = annotations.Get(pod.Annotations, volume, annotations.NFSServer)
+	path = annotations.Get(pod.Annotations, volume, annotations.NFSPath)
+	return server, path, server != "" && path != ""
+}
+
+// ensureNFSPair creates a statically provisioned NFS PersistentVolume and a
+// PVC pre-bound to it, for clusters without a dynamic provisioner. The PV is
+// cluster-scoped, so its name includes the namespace.
+func (c *Controller) ensureNFSPair(ctx context.Context, pod *corev1.Pod, volume, claim, server, path string) error {
+	pvc := c.claimForVolume(pod, volume, claim)
+	pvName := pod.Namespace + "-" + claim
+	if len(pvName) > 253 {
+		pvName = pvName[:253]
+	}
+
+	// Static binding: the claim must not fall through to the default
+	// dynamic provisioner, so its class is pinned to the empty string.
+	emptyClass := ""
+	pvc.Spec.StorageClassName = &emptyClass
+	pvc.Spec.VolumeName = pvName
+
+	capacity := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: pvName,
+			Labels: map[string]string{
+				managedByLabelKey: managedByLabelValue,
+			},
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: capacity,
+			},
+			AccessModes: pvc.Spec.AccessModes,
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				NFS: &corev1.NFSVolumeSource{
+					Server: server,
+					Path:   path,
+				},
+			},
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			ClaimRef: &corev1.ObjectReference{
+				Namespace: pod.Namespace,
+				Name:      claim,
+			},
+		},
+	}
+
+	if _, err := c.client.CoreV1().PersistentVolumes().Create(ctx, pv, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating NFS volume %q: %v", pvName, err)
+	}
+	if _, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Create(ctx, pvc, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating NFS-bound claim %q: %v", claim, err)
+	}
+	klog.Infof("created static NFS pair %s -> %s:%s for pod %s/%s volume %s", claim, server, path, pod.Namespace, pod.Name, volume)
+	return nil
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// The pause annotation on a Namespace suspends the controller's per-pod work
+// there — claim creation, topology pinning, gate lifting — during migrations
+// or incidents. Work resumes automatically once the annotation is removed;
+// nothing is forgotten because the pod watch relists and every pod event is
+// re-evaluated.
+
+// pauseCacheTTL is how long a namespace's pause state is cached, bounding
+// both the API load of per-pod checks and how stale a resume can be.
+const pauseCacheTTL = 30 * time.Second
+
+type pauseEntry struct {
+	paused      bool
+	terminating bool
+	checked     time.Time
+}
+
+// namespacePaused reports whether the namespace carries the pause annotation,
+// consulting a short-lived cache so a busy namespace does not turn every pod
+// event into a namespace GET.
+func (c *Controller) namespacePaused(ctx context.Context, namespace string) bool {
+	return c.namespaceState(ctx, namespace).paused
+}
+
+// namespaceTerminating reports whether the namespace is being deleted. Claim
+// creation in a terminating namespace is rejected by the API server anyway,
+// so the controller skips its pods instead of retrying forever.
+func (c *Controller) namespaceTerminating(ctx context.Context, namespace string) bool {
+	return c.namespaceState(ctx, namespace).terminating
+}
+
+// namespaceState fetches (or serves from cache) the namespace facts the
+// per-pod checks need.
+func (c *Controller) namespaceState(ctx context.Context, namespace string) pauseEntry {
+	c.pauseMu.Lock()
+	entry, ok := c.pauseCache[namespace]
+	c.pauseMu.Unlock()
+	if ok && time.Since(entry.checked) < pauseCacheTTL {
+		return entry
+	}
+
+	ns, err := c.client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Warningf("checking namespace %q: %v", namespace, err)
+			return pauseEntry{}
+		}
+		// A deleted namespace is trivially not paused; cache that so pods
+		// draining with it do not keep asking.
+	}
+	entry = pauseEntry{checked: time.Now()}
+	if err == nil {
+		entry.paused = ns.Annotations[annotations.NamespacePaused] == "true"
+		entry.terminating = ns.DeletionTimestamp != nil
+	}
+
+	c.pauseMu.Lock()
+	if c.pauseCache == nil {
+		c.pauseCache = map[string]pauseEntry{}
+	}
+	c.pauseCache[namespace] = entry
+	c.pauseMu.Unlock()
+	return entry
+}
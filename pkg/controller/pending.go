@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// runPendingMonitor exports how long each managed claim has been Pending and,
+// past the configured threshold, surfaces the provisioner's error message
+// onto the pods mounting it, so stuck provisioning is visible from the pod
+// without digging through PVC events.
+func (c *Controller) runPendingMonitor(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkPendingClaims(ctx)
+		}
+	}
+}
+
+func (c *Controller) checkPendingClaims(ctx context.Context) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("pending monitor: listing claims: %v", err)
+		return
+	}
+	now := time.Now()
+	metrics.PVCPendingSeconds.Reset()
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if pvc.Status.Phase != corev1.ClaimPending {
+			continue
+		}
+		pending := now.Sub(pvc.CreationTimestamp.Time)
+		metrics.PVCPendingSeconds.WithLabelValues(pvc.Namespace, pvc.Name).Set(pending.Seconds())
+		if c.cfg.PendingWarningThreshold <= 0 || pending < c.cfg.PendingWarningThreshold {
+			continue
+		}
+		c.warnPendingClaim(ctx, pvc, pending)
+	}
+}
+
+// warnPendingClaim emits a warning event on every pod mounting the stuck
+// claim, carrying the provisioner's latest error when one is recorded.
+func (c *Controller) warnPendingClaim(ctx context.Context, pvc *corev1.PersistentVolumeClaim, pending time.Duration) {
+	detail := c.provisionerError(ctx, pvc)
+	pods, err := c.client.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("pending monitor: listing pods for claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !podMountsClaim(pod, pvc.Name) {
+			continue
+		}
+		if detail != "" {
+			c.recorder.Eventf(pod, corev1.EventTypeWarning, "ClaimPending",
+				"PersistentVolumeClaim %q has been Pending for %s: %s", pvc.Name, pending.Round(time.Second), detail)
+		} else {
+			c.recorder.Eventf(pod, corev1.EventTypeWarning, "ClaimPending",
+				"PersistentVolumeClaim %q has been Pending for %s", pvc.Name, pending.Round(time.Second))
+		}
+	}
+}
+
+// provisionerError returns the newest warning message recorded against the
+// claim, typically the provisioner's failure reason, or "".
+func (c *Controller) provisionerError(ctx context.Context, pvc *corev1.PersistentVolumeClaim) string {
+	events, err := c.client.CoreV1().Events(pvc.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=PersistentVolumeClaim,involvedObject.name=" + pvc.Name,
+	})
+	if err != nil {
+		klog.V(2).Infof("pending monitor: listing events for claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return ""
+	}
+	var newest *corev1.Event
+	for i := range events.Items {
+		ev := &events.Items[i]
+		if ev.Type != corev1.EventTypeWarning {
+			continue
+		}
+		if newest == nil || ev.LastTimestamp.After(newest.LastTimestamp.Time) {
+			newest = ev
+		}
+	}
+	if newest == nil {
+		return ""
+	}
+	return newest.Message
+}
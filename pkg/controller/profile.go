@@ -0,0 +1,39 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Profile bundles claim parameters under an operator-chosen name (gold,
+// silver, bronze, ...), so pods request a policy tier instead of raw storage
+// parameters. Explicit per-volume annotations still win over the profile,
+// which in turn wins over the controller defaults.
+type Profile struct {
+	// StorageClass used for claims of this profile.
+	StorageClass string `json:"storageClass"`
+	// Size is the storage request, e.g. "100Gi".
+	Size string `json:"size"`
+	// AccessModes is a comma-separated access mode list.
+	AccessModes string `json:"accessModes"`
+	// ReclaimPolicy is applied to the bound PersistentVolume.
+	ReclaimPolicy string `json:"reclaimPolicy"`
+}
+
+// profileFor resolves the profile a pod volume requests via the profile
+// annotation. Unknown names fall back to the zero profile so the claim is
+// still created with the defaults.
+func (c *Controller) profileFor(pod *corev1.Pod, volume string) Profile {
+	name := annotations.Get(pod.Annotations, volume, annotations.Profile)
+	if name == "" {
+		return Profile{}
+	}
+	profile, ok := c.cfg.Profiles[name]
+	if !ok {
+		klog.Warningf("unknown storage profile %q for pod %s/%s volume %s, using defaults", name, pod.Namespace, pod.Name, volume)
+		return Profile{}
+	}
+	return profile
+}
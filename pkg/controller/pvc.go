@@ -0,0 +1,425 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// managedByLabel marks every claim created by this controller so cleanup
+// tooling can find them.
+const (
+	managedByLabelKey   = "created-by"
+	managedByLabelValue = "pvc-webhook"
+)
+
+// annotationSourceVolume records on a claim which pod volume it was created
+// for, so name collisions between unrelated volumes can be detected.
+const annotationSourceVolume = "pvc-webhook/source-volume"
+
+// fieldManager identifies this controller's field ownership for server-side
+// apply. Conflicts against other managers are surfaced rather than forced.
+const fieldManager = "pvc-webhook-controller"
+
+// ensureClaim applies the PVC for a converted volume, creating it if missing
+// and converging labels and annotations back to desired state if it exists.
+func (c *Controller) ensureClaim(ctx context.Context, pod *corev1.Pod, volume, claim string) error {
+	existing, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+	exists := err == nil
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("getting claim: %v", err)
+	}
+	if exists {
+		if src := existing.Annotations[annotationSourceVolume]; src != "" && src != volume {
+			return fmt.Errorf("claim name collision: %s/%s was created for volume %q, wanted for %q", pod.Namespace, claim, src, volume)
+		}
+		if existing.Labels[managedByLabelKey] != managedByLabelValue {
+			proceed, err := c.adoptForeignClaim(ctx, pod, volume, existing)
+			if err != nil || !proceed {
+				return err
+			}
+		}
+	} else if c.limiter != nil && !c.limiter.allow(pod.Namespace) {
+		c.deferClaim(pod, volume, claim)
+		return nil
+	}
+
+	// Static NFS backing bypasses dynamic provisioning entirely.
+	if server, path, ok := nfsSource(pod, volume); ok {
+		if exists {
+			return nil
+		}
+		return c.ensureNFSPair(ctx, pod, volume, claim, server, path)
+	}
+
+	pvc := c.claimForVolume(pod, volume, claim)
+	if err := c.applyPVCTemplate(ctx, pod, volume, pvc); err != nil {
+		return err
+	}
+	if err := c.applyDerivedStorageClass(ctx, pod, volume, pvc); err != nil {
+		return err
+	}
+	if err := c.applyCrossNamespaceDataSource(ctx, pod, volume, pvc); err != nil {
+		return err
+	}
+	if exists {
+		c.checkMultiPodAccess(ctx, pod, volume, existing)
+		c.reconcileClaimDrift(pod, volume, existing, pvc)
+	} else {
+		c.maybeUpgradeSharedAccess(ctx, pod, volume, pvc)
+		allowed, err := c.budgetAllows(ctx, pod, claim, pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+		if err != nil {
+			return fmt.Errorf("checking storage budget: %v", err)
+		}
+		if !allowed {
+			c.enqueueClaim(pod, volume, claim)
+			return nil
+		}
+	}
+
+	if err := c.applyClaim(ctx, pvc); err != nil {
+		return err
+	}
+	if !exists {
+		klog.Infof("created claim %s/%s for pod %s volume %s", pod.Namespace, claim, pod.Name, volume)
+		c.updateBudgetStatus(ctx, pod.Namespace)
+	}
+	return nil
+}
+
+// applyClaim server-side applies the desired claim under this controller's
+// field manager. Apply is an upsert, so one call covers creation and later
+// reconciles, and fields other managers took over produce a conflict instead
+// of being silently overwritten.
+func (c *Controller) applyClaim(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
+	pvc.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"}
+	data, err := json.Marshal(pvc)
+	if err != nil {
+		return fmt.Errorf("encoding claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+	}
+	_, err = c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: fieldManager,
+	})
+	if errors.IsConflict(err) {
+		return fmt.Errorf("applying claim %s/%s: another field manager owns conflicting fields: %v", pvc.Namespace, pvc.Name, err)
+	}
+	if err != nil {
+		return fmt.Errorf("applying claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+	}
+	return nil
+}
+
+// claimForVolume builds the PVC spec for a converted volume from the pod's
+// annotations, falling back to configured defaults.
+func (c *Controller) claimForVolume(pod *corev1.Pod, volume, claim string) *corev1.PersistentVolumeClaim {
+	profile := c.profileFor(pod, volume)
+	classFallback := profile.StorageClass
+	if classFallback == "" {
+		classFallback = c.cfg.DefaultStorageClass
+	}
+	class := storageClass(pod, volume, classFallback)
+
+	// The default size may depend on the selected storage class, so cheap
+	// tiers can default large and expensive tiers small.
+	size := annotations.Get(pod.Annotations, volume, annotations.Size)
+	if size == "" {
+		size = profile.Size
+	}
+	if size == "" {
+		size = c.cfg.ClassDefaultSizes[class]
+	}
+	if size == "" {
+		size = c.cfg.DefaultSize
+	}
+	qty, err := annotations.ParseSize(size)
+	if err != nil {
+		klog.Warningf("invalid size %q for pod %s/%s volume %s, using %s", size, pod.Namespace, pod.Name, volume, c.cfg.DefaultSize)
+		qty = resource.MustParse(c.cfg.DefaultSize)
+	}
+
+	// Shared volumes default to ReadWriteMany so every replica of the
+	// owning workload can mount the single claim. Otherwise, an emptyDir
+	// is inherently single-pod, so ReadWriteOncePod is the tightest
+	// default when enabled and the cluster supports it.
+	modesValue := annotations.Get(pod.Annotations, volume, annotations.AccessModes)
+	if modesValue == "" {
+		modesValue = profile.AccessModes
+	}
+	modes := accessModes(modesValue)
+	if modesValue == "" {
+		if annotations.Get(pod.Annotations, volume, annotations.Shared) == "true" {
+			modes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+		} else if c.cfg.PreferReadWriteOncePod && c.readWriteOncePodSupported() {
+			modes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}
+		}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claim,
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				managedByLabelKey: managedByLabelValue,
+			},
+			Annotations: map[string]string{
+				annotationSourceVolume: volume,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: modes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: qty,
+				},
+			},
+		},
+	}
+	// Backup-tool metadata makes converted volumes visible to existing
+	// backup policies without per-workload configuration.
+	for k, v := range c.cfg.BackupLabels {
+		pvc.Labels[k] = v
+	}
+	for k, v := range c.cfg.BackupAnnotations {
+		pvc.Annotations[k] = v
+	}
+
+	// CSI parameter passthrough: driver-specific tuning (replication
+	// factor, media type) lands on the claim as annotations the driver
+	// understands.
+	if params := annotations.Get(pod.Annotations, volume, annotations.CSIParams); params != "" {
+		for _, pair := range strings.Split(params, ",") {
+			parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				klog.Warningf("ignoring malformed csi-params entry %q for pod %s/%s volume %s", pair, pod.Namespace, pod.Name, volume)
+				continue
+			}
+			if strings.HasPrefix(parts[0], "pvc-webhook") {
+				klog.Warningf("refusing csi-params override of reserved key %q", parts[0])
+				continue
+			}
+			pvc.Annotations[parts[0]] = parts[1]
+		}
+	}
+
+	// VolumeAttributesClass selection is feature-gated and only applied
+	// when the cluster actually serves the API (K8s 1.29+).
+	if vac := annotations.Get(pod.Annotations, volume, annotations.VolumeAttributesClass); vac != "" {
+		if c.cfg.EnableVolumeAttributesClasses && c.volumeAttributesClassSupported() {
+			pvc.Spec.VolumeAttributesClassName = &vac
+		} else {
+			klog.Warningf("ignoring volumeAttributesClass %q for pod %s/%s volume %s: feature disabled or unsupported", vac, pod.Namespace, pod.Name, volume)
+		}
+	}
+
+	// The desired PV reclaim policy is recorded on the claim; it can only
+	// be applied to the volume once the claim binds.
+	rp := annotations.Get(pod.Annotations, volume, annotations.ReclaimPolicy)
+	if rp == "" {
+		rp = profile.ReclaimPolicy
+	}
+	if rp != "" {
+		if validReclaimPolicy(rp) {
+			pvc.Annotations[annotationReclaimPolicy] = rp
+		} else {
+			klog.Warningf("ignoring unknown reclaimPolicy %q for pod %s/%s volume %s", rp, pod.Namespace, pod.Name, volume)
+		}
+	}
+
+	// A volumeName annotation pins the claim to a pre-provisioned
+	// PersistentVolume for manual/static binding.
+	if pv := annotations.Get(pod.Annotations, volume, annotations.VolumeName); pv != "" {
+		pvc.Spec.VolumeName = pv
+	}
+
+	// A selector annotation restricts binding to pre-provisioned
+	// PersistentVolumes carrying the given labels, the static-binding
+	// analogue of choosing a storage class.
+	if sel := annotations.Get(pod.Annotations, volume, annotations.Selector); sel != "" {
+		matchLabels := map[string]string{}
+		if err := json.Unmarshal([]byte(sel), &matchLabels); err != nil {
+			klog.Warningf("ignoring invalid selector %q for pod %s/%s volume %s: %v", sel, pod.Namespace, pod.Name, volume, err)
+		} else {
+			pvc.Spec.Selector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		}
+	}
+
+	// A volumeMode annotation requests a raw Block device instead of the
+	// default Filesystem.
+	if mode := annotations.Get(pod.Annotations, volume, annotations.VolumeMode); mode != "" {
+		switch vm := corev1.PersistentVolumeMode(mode); vm {
+		case corev1.PersistentVolumeFilesystem, corev1.PersistentVolumeBlock:
+			pvc.Spec.VolumeMode = &vm
+		default:
+			klog.Warningf("ignoring unknown volumeMode %q for pod %s/%s volume %s", mode, pod.Namespace, pod.Name, volume)
+		}
+	}
+
+	// A data-source annotation prepopulates the volume via a populator
+	// (AnyVolumeDataSource), so the claim starts filled instead of empty.
+	if ds := annotations.Get(pod.Annotations, volume, annotations.DataSource); ds != "" {
+		if ref := parseDataSourceRef(ds); ref != nil {
+			pvc.Spec.DataSourceRef = ref
+		} else {
+			klog.Warningf("invalid data-source %q for pod %s/%s volume %s, want [apiGroup/]Kind/name", ds, pod.Namespace, pod.Name, volume)
+		}
+	}
+
+	// A keepLast annotation opts the claim into per-workload generation
+	// retention; the group and count are recorded on the claim for the
+	// sweeper.
+	if keep := annotations.Get(pod.Annotations, volume, annotations.KeepLast); keep != "" {
+		if n, err := strconv.Atoi(keep); err != nil || n < 1 {
+			klog.Warningf("ignoring invalid keepLast %q for pod %s/%s volume %s", keep, pod.Namespace, pod.Name, volume)
+		} else {
+			pvc.Annotations[annotationRetentionGroup] = retentionGroup(pod, volume)
+			pvc.Annotations[annotationKeepLast] = keep
+		}
+	}
+
+	// A ttlAfterPodDeletion annotation is copied onto the claim so the
+	// sweeper can compute the deadline without the (deleted) pod.
+	if ttl := annotations.Get(pod.Annotations, volume, annotations.TTLAfterPodDeletion); ttl != "" {
+		if _, err := time.ParseDuration(ttl); err != nil {
+			klog.Warningf("ignoring invalid ttlAfterPodDeletion %q for pod %s/%s volume %s: %v", ttl, pod.Namespace, pod.Name, volume, err)
+		} else {
+			pvc.Annotations[annotationTTLAfterPodDeletion] = ttl
+		}
+	}
+
+	// A fromSnapshot annotation restores the claim from a named
+	// VolumeSnapshot in the pod's namespace, so a new pod comes up with
+	// previously captured state. An explicit data-source wins when both are
+	// set.
+	if snap := annotations.Get(pod.Annotations, volume, annotations.FromSnapshot); snap != "" {
+		if pvc.Spec.DataSourceRef != nil {
+			klog.Warningf("ignoring fromSnapshot %q for pod %s/%s volume %s: data-source already set", snap, pod.Namespace, pod.Name, volume)
+		} else {
+			group := volumeSnapshotClassGVR.Group
+			pvc.Spec.DataSourceRef = &corev1.TypedObjectReference{
+				APIGroup: &group,
+				Kind:     "VolumeSnapshot",
+				Name:     snap,
+			}
+		}
+	}
+
+	// Identity-named and shared claims deliberately outlive any single pod
+	// using them, so they carry no owner reference and are left to
+	// retention policy.
+	if annotations.Get(pod.Annotations, volume, annotations.Identity) == "" &&
+		annotations.Get(pod.Annotations, volume, annotations.Shared) != "true" {
+		pvc.OwnerReferences = []metav1.OwnerReference{claimOwner(pod)}
+	}
+	if class != "" {
+		pvc.Spec.StorageClassName = &class
+	}
+	return pvc
+}
+
+// claimOwner picks the owner reference for a created claim. Claims for pods
+// controlled by a Job are owned by the Job instead of the pod, so retried
+// pods can reuse the claim and garbage collection follows the Job (including
+// TTL-based cleanup).
+func claimOwner(pod *corev1.Pod) metav1.OwnerReference {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller && ref.Kind == "Job" && strings.HasPrefix(ref.APIVersion, "batch/") {
+			owner := ref
+			return owner
+		}
+	}
+	return *metav1.NewControllerRef(pod, corev1.SchemeGroupVersion.WithKind("Pod"))
+}
+
+// applyDerivedStorageClass redirects the claim at a derived StorageClass
+// when the volume requests per-claim storage class parameters and the
+// feature is enabled.
+func (c *Controller) applyDerivedStorageClass(ctx context.Context, pod *corev1.Pod, volume string, pvc *corev1.PersistentVolumeClaim) error {
+	if !c.cfg.DerivedStorageClasses {
+		return nil
+	}
+	raw := annotations.Get(pod.Annotations, volume, annotations.StorageClassParams)
+	if raw == "" {
+		return nil
+	}
+	params := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			klog.Warningf("ignoring malformed storage-class-params entry %q for pod %s/%s volume %s", pair, pod.Namespace, pod.Name, volume)
+			continue
+		}
+		params[parts[0]] = parts[1]
+	}
+	if len(params) == 0 {
+		return nil
+	}
+
+	base := c.cfg.DefaultStorageClass
+	if pvc.Spec.StorageClassName != nil {
+		base = *pvc.Spec.StorageClassName
+	}
+	derived, err := c.derivedStorageClass(ctx, base, params)
+	if err != nil {
+		return fmt.Errorf("deriving storage class for volume %q: %v", volume, err)
+	}
+	pvc.Spec.StorageClassName = &derived
+	return nil
+}
+
+// parseDataSourceRef parses "[apiGroup/]Kind/name" into a typed reference,
+// returning nil when the value is malformed.
+func parseDataSourceRef(value string) *corev1.TypedObjectReference {
+	parts := strings.Split(value, "/")
+	switch len(parts) {
+	case 2:
+		return &corev1.TypedObjectReference{Kind: parts[0], Name: parts[1]}
+	case 3:
+		group := parts[0]
+		return &corev1.TypedObjectReference{APIGroup: &group, Kind: parts[1], Name: parts[2]}
+	}
+	return nil
+}
+
+func storageClass(pod *corev1.Pod, volume, fallback string) string {
+	if sc := annotations.Get(pod.Annotations, volume, annotations.StorageClass); sc != "" {
+		return sc
+	}
+	return fallback
+}
+
+// accessModes parses a comma-separated access mode list, defaulting to
+// ReadWriteOnce when the annotation is absent or unparsable.
+func accessModes(value string) []corev1.PersistentVolumeAccessMode {
+	if value == "" {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	var modes []corev1.PersistentVolumeAccessMode
+	for _, m := range strings.Split(value, ",") {
+		switch strings.TrimSpace(m) {
+		case "ReadWriteOnce", "RWO":
+			modes = append(modes, corev1.ReadWriteOnce)
+		case "ReadOnlyMany", "ROX":
+			modes = append(modes, corev1.ReadOnlyMany)
+		case "ReadWriteMany", "RWX":
+			modes = append(modes, corev1.ReadWriteMany)
+		case "ReadWriteOncePod", "RWOP":
+			modes = append(modes, corev1.ReadWriteOncePod)
+		}
+	}
+	if len(modes) == 0 {
+		modes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return modes
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// namespaceLimiter hands out one token bucket per namespace so a single
+// namespace mass-creating pods cannot flood the storage backend with
+// provisioning requests.
+type namespaceLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+	qps      rate.Limit
+	burst    int
+}
+
+func newNamespaceLimiter(qps float64, burst int) *namespaceLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &namespaceLimiter{
+		limiters: map[string]*rate.Limiter{},
+		qps:      rate.Limit(qps),
+		burst:    burst,
+	}
+}
+
+// allow reports whether a claim creation in the namespace may proceed now.
+func (l *namespaceLimiter) allow(namespace string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lim, ok := l.limiters[namespace]
+	if !ok {
+		lim = rate.NewLimiter(l.qps, l.burst)
+		l.limiters[namespace] = lim
+	}
+	return lim.Allow()
+}
+
+// deferredClaim is a claim creation held back by the rate limiter.
+type deferredClaim struct {
+	pod    *corev1.Pod
+	volume string
+	claim  string
+}
+
+// enqueueClaim adds a creation to the retry queue.
+func (c *Controller) enqueueClaim(pod *corev1.Pod, volume, claim string) {
+	c.pendingMu.Lock()
+	c.pending = append(c.pending, deferredClaim{pod: pod, volume: volume, claim: claim})
+	c.pendingMu.Unlock()
+}
+
+// deferClaim queues a rate-limited creation for retry and tells the user why
+// their volume is not there yet.
+func (c *Controller) deferClaim(pod *corev1.Pod, volume, claim string) {
+	c.enqueueClaim(pod, volume, claim)
+	klog.Infof("rate limit: deferring claim %s/%s for pod %s", pod.Namespace, claim, pod.Name)
+	if c.recorder != nil {
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "ClaimCreationThrottled",
+			"creation of PersistentVolumeClaim %q is rate limited in this namespace and will be retried", claim)
+	}
+}
+
+// runPendingRetries periodically retries deferred claim creations as tokens
+// become available.
+func (c *Controller) runPendingRetries(ctx context.Context) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.retryPending(ctx)
+		}
+	}
+}
+
+func (c *Controller) retryPending(ctx context.Context) {
+	c.pendingMu.Lock()
+	queued := c.pending
+	c.pending = nil
+	c.pendingMu.Unlock()
+
+	// ensureClaim re-defers entries that are still over the limit.
+	for _, d := range queued {
+		if err := c.ensureClaim(ctx, d.pod, d.volume, d.claim); err != nil {
+			klog.Errorf("retrying deferred claim %s/%s: %v", d.pod.Namespace, d.claim, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// annotationReclaimPolicy carries the desired PV reclaim policy from the pod
+// annotation onto the claim, where the claim watch applies it after binding.
+const annotationReclaimPolicy = "pvc-webhook/reclaim-policy"
+
+// validReclaimPolicy reports whether the value is a known reclaim policy.
+func validReclaimPolicy(value string) bool {
+	switch corev1.PersistentVolumeReclaimPolicy(value) {
+	case corev1.PersistentVolumeReclaimRetain, corev1.PersistentVolumeReclaimDelete, corev1.PersistentVolumeReclaimRecycle:
+		return true
+	}
+	return false
+}
+
+// applyReclaimPolicy patches the reclaim policy of the PV bound to a managed
+// claim once binding completes, e.g. to Retain so data survives claim
+// deletion. The change is recorded as an event on the claim.
+func (c *Controller) applyReclaimPolicy(ctx context.Context, pvc *corev1.PersistentVolumeClaim) {
+	want := pvc.Annotations[annotationReclaimPolicy]
+	if want == "" || pvc.Status.Phase != corev1.ClaimBound || pvc.Spec.VolumeName == "" {
+		return
+	}
+	if !validReclaimPolicy(want) {
+		klog.Warningf("claim %s/%s requests unknown reclaim policy %q", pvc.Namespace, pvc.Name, want)
+		return
+	}
+
+	pv, err := c.client.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("getting volume %q for claim %s/%s: %v", pvc.Spec.VolumeName, pvc.Namespace, pvc.Name, err)
+		return
+	}
+	if string(pv.Spec.PersistentVolumeReclaimPolicy) == want {
+		return
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"persistentVolumeReclaimPolicy":%q}}`, want))
+	if _, err := c.client.CoreV1().PersistentVolumes().Patch(ctx, pv.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("patching reclaim policy of volume %q: %v", pv.Name, err)
+		return
+	}
+	klog.Infof("set reclaim policy of volume %q to %s for claim %s/%s", pv.Name, want, pvc.Namespace, pvc.Name)
+	c.recorder.Eventf(pvc, corev1.EventTypeNormal, "ReclaimPolicyChanged",
+		"changed reclaim policy of PersistentVolume %q from %s to %s", pv.Name, pv.Spec.PersistentVolumeReclaimPolicy, want)
+}
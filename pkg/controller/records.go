@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+)
+
+// conversionRecordGVR locates ConversionRecord objects via the dynamic client.
+var conversionRecordGVR = schema.GroupVersionResource{
+	Group:    v1alpha1.GroupName,
+	Version:  "v1alpha1",
+	Resource: "conversionrecords",
+}
+
+// sweepConversionRecords deletes ConversionRecord audit objects older than the
+// configured TTL. The webhook writes the records; this sweep is the only thing
+// that removes them, so a disabled TTL means records accumulate until an
+// operator cleans up.
+func (c *Controller) sweepConversionRecords(ctx context.Context) {
+	if c.cfg.ConversionRecordTTL <= 0 || c.dynamic == nil {
+		return
+	}
+	list, err := c.dynamic.Resource(conversionRecordGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.V(4).Infof("sweeper: listing ConversionRecords (CRD missing or inaccessible?): %v", err)
+		return
+	}
+	cutoff := time.Now().Add(-c.cfg.ConversionRecordTTL)
+	for i := range list.Items {
+		record := &list.Items[i]
+		if record.GetCreationTimestamp().Time.After(cutoff) {
+			continue
+		}
+		if err := c.dynamic.Resource(conversionRecordGVR).Namespace(record.GetNamespace()).Delete(ctx, record.GetName(), metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("sweeper: deleting expired ConversionRecord %s/%s: %v", record.GetNamespace(), record.GetName(), err)
+			continue
+		}
+		klog.V(2).Infof("sweeper: deleted expired ConversionRecord %s/%s", record.GetNamespace(), record.GetName())
+	}
+}
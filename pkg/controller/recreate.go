@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Out-of-band deletion: when someone deletes a managed claim while a pod
+// still references it, the running pod keeps its mounted volume until exit,
+// so the mistake surfaces only when the workload restarts and fails to find
+// the claim. The claim watch catches the deletion while the pod is alive,
+// warns, and by default recreates the claim so a replacement pod can start.
+
+// handleClaimDeleted reacts to a managed claim disappearing: every live pod
+// still referencing it gets a warning event, and the claim is recreated from
+// the pod's annotations unless recreation is disabled.
+func (c *Controller) handleClaimDeleted(ctx context.Context, pvc *corev1.PersistentVolumeClaim) {
+	if c.namespacePaused(ctx, pvc.Namespace) || c.namespaceTerminating(ctx, pvc.Namespace) {
+		return
+	}
+	pods, err := c.client.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("listing pods after deletion of claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		volume := ""
+		for vol, claim := range annotations.Claims(pod.Annotations) {
+			if claim == pvc.Name {
+				volume = vol
+				break
+			}
+		}
+		if volume == "" {
+			continue
+		}
+		klog.Warningf("managed claim %s/%s was deleted out-of-band while pod %s still references it", pvc.Namespace, pvc.Name, pod.Name)
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "ClaimDeleted",
+			"PersistentVolumeClaim %q was deleted while this pod references it; the mounted volume survives only until the pod exits", pvc.Name)
+		if !c.cfg.RecreateDeletedClaims {
+			continue
+		}
+		if err := c.ensureClaim(ctx, pod, volume, pvc.Name); err != nil {
+			klog.Errorf("recreating deleted claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			continue
+		}
+		klog.Infof("recreated claim %s/%s deleted out-of-band, still referenced by pod %s", pvc.Namespace, pvc.Name, pod.Name)
+		c.recorder.Eventf(pod, corev1.EventTypeNormal, "ClaimRecreated",
+			"recreated PersistentVolumeClaim %q so replacement pods can start", pvc.Name)
+	}
+}
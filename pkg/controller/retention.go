@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// Keep-last-N retention: claims whose volume carried a keepLast annotation
+// are grouped by their owning workload, and the sweeper keeps only the newest
+// N generations per group, balancing recoverability against storage cost.
+const (
+	// annotationRetentionGroup names the workload-scoped group a claim
+	// belongs to for keep-last retention.
+	annotationRetentionGroup = "pvc-webhook/retention-group"
+	// annotationKeepLast carries the generation count copied from the pod's
+	// volume annotation onto the claim.
+	annotationKeepLast = "pvc-webhook/keep-last"
+)
+
+// retentionGroup derives a name stable across all generations of the pod's
+// owning workload, stripping the per-revision ReplicaSet hash the same way
+// claim naming does.
+func retentionGroup(pod *corev1.Pod, volume string) string {
+	base := pod.Name
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		base = ref.Name
+		if ref.Kind == "ReplicaSet" {
+			if i := strings.LastIndex(base, "-"); i > 0 {
+				base = base[:i]
+			}
+		}
+		break
+	}
+	return base + "/" + volume
+}
+
+// sweepRetention enforces keep-last-N per retention group, deleting the
+// oldest claims beyond the limit. Only orphaned claims are removed; a claim
+// still mounted or owned stays regardless of its generation.
+func (c *Controller) sweepRetention(ctx context.Context) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("retention: listing managed claims: %v", err)
+		return
+	}
+	groups := map[string][]*corev1.PersistentVolumeClaim{}
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if group := pvc.Annotations[annotationRetentionGroup]; group != "" {
+			key := pvc.Namespace + "/" + group
+			groups[key] = append(groups[key], pvc)
+		}
+	}
+
+	for key, members := range groups {
+		sort.Slice(members, func(i, j int) bool {
+			return members[i].CreationTimestamp.After(members[j].CreationTimestamp.Time)
+		})
+		// The newest generation's annotation wins, so lowering N on a
+		// workload takes effect on the next rollout.
+		keep, err := strconv.Atoi(members[0].Annotations[annotationKeepLast])
+		if err != nil || keep < 1 {
+			klog.Warningf("retention: group %s carries invalid keep-last %q, skipping", key, members[0].Annotations[annotationKeepLast])
+			continue
+		}
+		for _, pvc := range members[keep:] {
+			orphaned, err := c.claimOrphaned(ctx, pvc)
+			if err != nil {
+				klog.Errorf("retention: checking claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+				continue
+			}
+			if !orphaned {
+				continue
+			}
+			if err := c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				klog.Errorf("retention: deleting claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+				continue
+			}
+			klog.Infof("retention: deleted claim %s/%s beyond keep-last %d for group %s", pvc.Namespace, pvc.Name, keep, key)
+		}
+	}
+}
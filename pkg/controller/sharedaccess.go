@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Access modes are immutable once a claim exists, so sharing has to be decided
+// up front: identity- and shared-named claims request ReadWriteMany when their
+// storage class can serve it, and a second pod arriving at a single-writer
+// claim gets a clear warning instead of a Multi-Attach error at mount time.
+
+// rwxProvisionerHints are substrings of provisioner names known to serve
+// ReadWriteMany volumes. The API cannot express access-mode support, so this
+// list is the best signal available.
+var rwxProvisionerHints = []string{
+	"nfs",
+	"cephfs",
+	"efs.csi",
+	"file.csi",
+	"filestore",
+	"smb",
+	"glusterfs",
+}
+
+// classSupportsRWX reports whether the storage class's provisioner is known to
+// serve ReadWriteMany volumes. Lookups are cached for the process lifetime;
+// provisioners of existing classes do not change.
+func (c *Controller) classSupportsRWX(ctx context.Context, class string) bool {
+	if class == "" {
+		return false
+	}
+	c.provisionerMu.Lock()
+	provisioner, cached := c.provisioners[class]
+	c.provisionerMu.Unlock()
+	if !cached {
+		sc, err := c.client.StorageV1().StorageClasses().Get(ctx, class, metav1.GetOptions{})
+		if err != nil {
+			klog.V(2).Infof("looking up storage class %q: %v", class, err)
+			return false
+		}
+		provisioner = sc.Provisioner
+		c.provisionerMu.Lock()
+		if c.provisioners == nil {
+			c.provisioners = map[string]string{}
+		}
+		c.provisioners[class] = provisioner
+		c.provisionerMu.Unlock()
+	}
+	lower := strings.ToLower(provisioner)
+	for _, hint := range rwxProvisionerHints {
+		if strings.Contains(lower, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeUpgradeSharedAccess defaults an identity-named claim to ReadWriteMany
+// before creation when its storage class can serve it: identity naming exists
+// so unrelated pods can share one claim, and a second pod cannot mount a
+// ReadWriteOnce volume. Explicit access-mode annotations and profiles win.
+func (c *Controller) maybeUpgradeSharedAccess(ctx context.Context, pod *corev1.Pod, volume string, pvc *corev1.PersistentVolumeClaim) {
+	if annotations.Get(pod.Annotations, volume, annotations.Identity) == "" {
+		return
+	}
+	if annotations.Get(pod.Annotations, volume, annotations.AccessModes) != "" || c.profileFor(pod, volume).AccessModes != "" {
+		return
+	}
+	if claimAllowsMultiPod(pvc) {
+		return
+	}
+	class := c.cfg.DefaultStorageClass
+	if pvc.Spec.StorageClassName != nil {
+		class = *pvc.Spec.StorageClassName
+	}
+	if !c.classSupportsRWX(ctx, class) {
+		klog.V(2).Infof("leaving identity claim %s/%s single-writer: storage class %q is not known to support ReadWriteMany", pvc.Namespace, pvc.Name, class)
+		return
+	}
+	klog.Infof("requesting ReadWriteMany for identity claim %s/%s so multiple pods can mount it", pvc.Namespace, pvc.Name)
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+}
+
+// claimAllowsMultiPod reports whether the claim's access modes permit a second
+// pod to mount it.
+func claimAllowsMultiPod(pvc *corev1.PersistentVolumeClaim) bool {
+	for _, mode := range pvc.Spec.AccessModes {
+		if mode == corev1.ReadWriteMany || mode == corev1.ReadOnlyMany {
+			return true
+		}
+	}
+	return false
+}
+
+// secondPodMounting returns the name of a different live pod already mounting
+// the claim, or "".
+func (c *Controller) secondPodMounting(ctx context.Context, pod *corev1.Pod, claim string) string {
+	pods, err := c.client.CoreV1().Pods(pod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.V(2).Infof("listing pods for claim %s/%s: %v", pod.Namespace, claim, err)
+		return ""
+	}
+	for i := range pods.Items {
+		other := &pods.Items[i]
+		if other.Name == pod.Name {
+			continue
+		}
+		if other.Status.Phase == corev1.PodSucceeded || other.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if podMountsClaim(other, claim) {
+			return other.Name
+		}
+	}
+	return ""
+}
+
+// checkMultiPodAccess warns when the pod is about to share a claim whose
+// access modes cannot support a second consumer, naming the fix in the event
+// so users do not have to debug a Multi-Attach error later.
+func (c *Controller) checkMultiPodAccess(ctx context.Context, pod *corev1.Pod, volume string, existing *corev1.PersistentVolumeClaim) {
+	if claimAllowsMultiPod(existing) {
+		return
+	}
+	other := c.secondPodMounting(ctx, pod, existing.Name)
+	if other == "" {
+		return
+	}
+	class := ""
+	if existing.Spec.StorageClassName != nil {
+		class = *existing.Spec.StorageClassName
+	}
+	remedy := "use a storage class that supports ReadWriteMany"
+	if c.classSupportsRWX(ctx, class) {
+		remedy = "annotate the volume with " + annotations.Key(volume, annotations.AccessModes) + "=ReadWriteMany and recreate the claim"
+	}
+	klog.Errorf("claim %s/%s is %v and already mounted by pod %s; pod %s will hit a Multi-Attach error (%s)",
+		existing.Namespace, existing.Name, existing.Spec.AccessModes, other, pod.Name, remedy)
+	c.recorder.Eventf(pod, corev1.EventTypeWarning, "MultiAttachRisk",
+		"claim %q is already mounted by pod %q but only allows %v; %s",
+		existing.Name, other, existing.Spec.AccessModes, remedy)
+}
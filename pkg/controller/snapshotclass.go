@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+// volumeSnapshotClassGVR locates VolumeSnapshotClass objects (an external
+// CRD shipped with the CSI snapshotter) via the dynamic client.
+var volumeSnapshotClassGVR = schema.GroupVersionResource{
+	Group:    "snapshot.storage.k8s.io",
+	Version:  "v1",
+	Resource: "volumesnapshotclasses",
+}
+
+// isDefaultSnapshotClass is the upstream annotation marking a driver's
+// default VolumeSnapshotClass.
+const isDefaultSnapshotClass = "snapshot.storage.kubernetes.io/is-default-class"
+
+// snapshotClassCache caches discovered snapshot classes per CSI driver, since
+// they change rarely and every snapshot operation needs one.
+type snapshotClassCache struct {
+	mu        sync.Mutex
+	byDriver  map[string]string
+	refreshed time.Time
+}
+
+// snapshotClassForDriver resolves the VolumeSnapshotClass to use for a CSI
+// driver: the configured override first, then the driver's annotated default,
+// then the only class for the driver.
+func (c *Controller) snapshotClassForDriver(ctx context.Context, driver string) (string, error) {
+	if c.cfg.DefaultSnapshotClass != "" {
+		return c.cfg.DefaultSnapshotClass, nil
+	}
+	if c.dynamic == nil {
+		return "", fmt.Errorf("snapshot support requires a dynamic client")
+	}
+
+	c.snapClasses.mu.Lock()
+	defer c.snapClasses.mu.Unlock()
+	if time.Since(c.snapClasses.refreshed) > 5*time.Minute || c.snapClasses.byDriver == nil {
+		if err := c.refreshSnapshotClassesLocked(ctx); err != nil {
+			return "", err
+		}
+	}
+	class, ok := c.snapClasses.byDriver[driver]
+	if !ok {
+		return "", fmt.Errorf("no VolumeSnapshotClass found for driver %q", driver)
+	}
+	return class, nil
+}
+
+// refreshSnapshotClassesLocked rebuilds the per-driver class map, preferring
+// classes annotated as the driver default.
+func (c *Controller) refreshSnapshotClassesLocked(ctx context.Context) error {
+	list, err := c.dynamic.Resource(volumeSnapshotClassGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing volume snapshot classes: %v", err)
+	}
+	byDriver := map[string]string{}
+	for _, item := range list.Items {
+		driver, _, _ := unstructuredString(item.Object, "driver")
+		if driver == "" {
+			continue
+		}
+		isDefault := item.GetAnnotations()[isDefaultSnapshotClass] == "true"
+		if _, exists := byDriver[driver]; !exists || isDefault {
+			byDriver[driver] = item.GetName()
+		}
+	}
+	c.snapClasses.byDriver = byDriver
+	c.snapClasses.refreshed = time.Now()
+	klog.V(2).Infof("discovered %d volume snapshot classes", len(byDriver))
+	return nil
+}
+
+// unstructuredString reads a top-level string field from an unstructured
+// object.
+func unstructuredString(obj map[string]interface{}, field string) (string, bool, error) {
+	v, ok := obj[field]
+	if !ok {
+		return "", false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", true, fmt.Errorf("field %q is not a string", field)
+	}
+	return s, true, nil
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// annotationStatus carries a JSON summary of every managed claim's phase,
+// written onto the pod so users debugging a Pending pod see storage state
+// without chasing PVCs.
+const annotationStatus = "pvc-webhook/status"
+
+// volumeStatus is one entry of the pvc-webhook/status annotation.
+type volumeStatus struct {
+	Claim string `json:"claim"`
+	Phase string `json:"phase"`
+}
+
+// reportStatus writes the current claim phases onto the pod's status
+// annotation, skipping the update when nothing changed.
+func (c *Controller) reportStatus(ctx context.Context, pod *corev1.Pod) {
+	statuses := map[string]volumeStatus{}
+	for volume, claim := range annotations.Claims(pod.Annotations) {
+		pvc, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+		if err != nil {
+			statuses[volume] = volumeStatus{Claim: claim, Phase: "NotFound"}
+			continue
+		}
+		statuses[volume] = volumeStatus{Claim: claim, Phase: string(pvc.Status.Phase)}
+	}
+	if len(statuses) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(statuses)
+	if err != nil {
+		klog.Errorf("encoding status for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	if pod.Annotations[annotationStatus] == string(raw) {
+		return
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{annotationStatus: string(raw)},
+		},
+	})
+	if err != nil {
+		klog.Errorf("encoding status patch for pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	if _, err := c.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.V(2).Infof("patching status onto pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
+
+// runClaimWatch follows managed claims so phase transitions (e.g. Pending to
+// Bound) are reflected onto the referencing pods promptly.
+func (c *Controller) runClaimWatch(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		w, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).Watch(ctx, metav1.ListOptions{
+			LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+		})
+		if err != nil {
+			klog.Errorf("watching claims: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		c.consumeClaims(ctx, w)
+	}
+}
+
+func (c *Controller) consumeClaims(ctx context.Context, w watch.Interface) {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return
+			}
+			if ev.Type == watch.Deleted {
+				if pvc, ok := ev.Object.(*corev1.PersistentVolumeClaim); ok {
+					c.handleClaimDeleted(ctx, pvc)
+				}
+				continue
+			}
+			if ev.Type != watch.Modified && ev.Type != watch.Added {
+				continue
+			}
+			pvc, ok := ev.Object.(*corev1.PersistentVolumeClaim)
+			if !ok {
+				continue
+			}
+			c.observeBindLatency(pvc)
+			c.applyReclaimPolicy(ctx, pvc)
+			c.refreshClaimPods(ctx, pvc)
+		}
+	}
+}
+
+// observeBindLatency records the creation-to-Bound latency of a claim the
+// first time it is seen Bound.
+func (c *Controller) observeBindLatency(pvc *corev1.PersistentVolumeClaim) {
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return
+	}
+	c.boundSeenMu.Lock()
+	defer c.boundSeenMu.Unlock()
+	if c.boundSeen == nil {
+		c.boundSeen = map[types.UID]bool{}
+	}
+	if c.boundSeen[pvc.UID] {
+		return
+	}
+	c.boundSeen[pvc.UID] = true
+
+	class := ""
+	if pvc.Spec.StorageClassName != nil {
+		class = *pvc.Spec.StorageClassName
+	}
+	metrics.BindLatency.WithLabelValues(class).Observe(time.Since(pvc.CreationTimestamp.Time).Seconds())
+}
+
+// refreshClaimPods re-reports status on every pod mounting the claim.
+func (c *Controller) refreshClaimPods(ctx context.Context, pvc *corev1.PersistentVolumeClaim) {
+	pods, err := c.client.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("listing pods for claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvc.Name {
+				c.reportStatus(ctx, pod)
+				c.maybeLiftSchedulingGate(ctx, pod)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// derivedStorageClass returns the name of a StorageClass derived from base
+// with the extra parameters merged in, creating it if needed. Derived classes
+// are content-addressed by their parameters, so identical requests share one
+// class, and they are labeled for garbage collection.
+func (c *Controller) derivedStorageClass(ctx context.Context, base string, params map[string]string) (string, error) {
+	if base == "" {
+		return "", fmt.Errorf("per-claim storage class parameters require a base storage class")
+	}
+	baseSC, err := c.client.StorageV1().StorageClasses().Get(ctx, base, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("getting base storage class %q: %v", base, err)
+	}
+
+	name := base + "-" + paramsHash(params)
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	if _, err := c.client.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{}); err == nil {
+		return name, nil
+	} else if !errors.IsNotFound(err) {
+		return "", err
+	}
+
+	merged := map[string]string{}
+	for k, v := range baseSC.Parameters {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+
+	derived := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				managedByLabelKey: managedByLabelValue,
+			},
+			Annotations: map[string]string{
+				"pvc-webhook/derived-from": base,
+			},
+		},
+		Provisioner:          baseSC.Provisioner,
+		Parameters:           merged,
+		ReclaimPolicy:        baseSC.ReclaimPolicy,
+		MountOptions:         baseSC.MountOptions,
+		AllowVolumeExpansion: baseSC.AllowVolumeExpansion,
+		VolumeBindingMode:    baseSC.VolumeBindingMode,
+		AllowedTopologies:    baseSC.AllowedTopologies,
+	}
+	if _, err := c.client.StorageV1().StorageClasses().Create(ctx, derived, metav1.CreateOptions{}); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return name, nil
+		}
+		return "", fmt.Errorf("creating derived storage class %q: %v", name, err)
+	}
+	klog.Infof("created derived storage class %q from %q", name, base)
+	return name, nil
+}
+
+// sweepDerivedStorageClasses deletes derived classes no claim references
+// anymore.
+func (c *Controller) sweepDerivedStorageClasses(ctx context.Context) {
+	classes, err := c.client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("sweeper: listing derived storage classes: %v", err)
+		return
+	}
+	if len(classes.Items) == 0 {
+		return
+	}
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("sweeper: listing claims: %v", err)
+		return
+	}
+	referenced := map[string]bool{}
+	for i := range claims.Items {
+		if sc := claims.Items[i].Spec.StorageClassName; sc != nil {
+			referenced[*sc] = true
+		}
+	}
+	for i := range classes.Items {
+		name := classes.Items[i].Name
+		if referenced[name] {
+			continue
+		}
+		if err := c.client.StorageV1().StorageClasses().Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("sweeper: deleting derived storage class %q: %v", name, err)
+			continue
+		}
+		klog.Infof("sweeper: deleted unreferenced derived storage class %q", name)
+	}
+}
+
+// paramsHash returns a short stable hash of a parameter map.
+func paramsHash(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(params[k])
+		b.WriteByte(';')
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])[:8]
+}
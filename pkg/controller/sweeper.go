@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// annotationOrphanedAt is stamped on claims flagged as orphaned when the
+// sweeper runs in flag mode instead of deleting.
+const annotationOrphanedAt = "pvc-webhook/orphaned-at"
+
+// runSweeper periodically scans managed claims and handles those whose
+// referencing pod or owning workload is gone. Owner references alone do not
+// cover claims created by older releases or claims deliberately retained.
+func (c *Controller) runSweeper(ctx context.Context) {
+	if c.cfg.OrphanSweepInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.OrphanSweepInterval)
+	defer ticker.Stop()
+	firstSeen := map[string]time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.sweepOrphans(ctx, firstSeen)
+			c.sweepExpiredTTLs(ctx)
+			c.sweepRetention(ctx)
+			c.sweepStuckTerminating(ctx)
+			c.sweepConversionRecords(ctx)
+			if c.cfg.DerivedStorageClasses {
+				c.sweepDerivedStorageClasses(ctx)
+			}
+		}
+	}
+}
+
+// sweepOrphans examines every managed claim and deletes or flags the ones
+// that have been orphaned for longer than the grace period.
+func (c *Controller) sweepOrphans(ctx context.Context, firstSeen map[string]time.Time) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("sweeper: listing managed claims: %v", err)
+		return
+	}
+
+	now := time.Now()
+	seen := map[string]bool{}
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		key := pvc.Namespace + "/" + pvc.Name
+		seen[key] = true
+
+		// TTL-governed claims have an explicit retention window; deleting
+		// them as orphans would defeat it.
+		if claimTTLGoverned(pvc) {
+			delete(firstSeen, key)
+			continue
+		}
+		orphaned, err := c.claimOrphaned(ctx, pvc)
+		if err != nil {
+			klog.Errorf("sweeper: checking claim %s: %v", key, err)
+			continue
+		}
+		if !orphaned {
+			delete(firstSeen, key)
+			continue
+		}
+		since, ok := firstSeen[key]
+		if !ok {
+			firstSeen[key] = now
+			continue
+		}
+		if now.Sub(since) < c.cfg.OrphanGracePeriod {
+			continue
+		}
+		c.handleOrphan(ctx, pvc)
+		delete(firstSeen, key)
+	}
+
+	// Drop bookkeeping for claims that disappeared between sweeps.
+	for key := range firstSeen {
+		if !seen[key] {
+			delete(firstSeen, key)
+		}
+	}
+}
+
+// claimOrphaned reports whether nothing references the claim anymore: its
+// owners no longer exist and no pod in the namespace mounts it.
+func (c *Controller) claimOrphaned(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	for _, ref := range pvc.OwnerReferences {
+		exists, err := c.ownerExists(ctx, pvc.Namespace, ref)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return false, nil
+		}
+	}
+
+	pods, err := c.client.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for i := range pods.Items {
+		for _, vol := range pods.Items[i].Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvc.Name {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+func (c *Controller) ownerExists(ctx context.Context, namespace string, ref metav1.OwnerReference) (bool, error) {
+	var uid types.UID
+	switch ref.Kind {
+	case "Pod":
+		pod, err := c.client.CoreV1().Pods(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err == nil {
+			uid = pod.UID
+		} else if !errors.IsNotFound(err) {
+			return false, err
+		}
+	case "Job":
+		job, err := c.client.BatchV1().Jobs(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err == nil {
+			uid = job.UID
+		} else if !errors.IsNotFound(err) {
+			return false, err
+		}
+	default:
+		// Unknown owner kinds are treated as alive; better to retain a
+		// claim than to delete data behind an owner we cannot check.
+		return true, nil
+	}
+	return uid == ref.UID, nil
+}
+
+// handleOrphan deletes or flags an orphaned claim according to configuration.
+func (c *Controller) handleOrphan(ctx context.Context, pvc *corev1.PersistentVolumeClaim) {
+	if c.cfg.OrphanAction == OrphanFlag {
+		if pvc.Annotations[annotationOrphanedAt] != "" {
+			return
+		}
+		patch := []byte(`{"metadata":{"annotations":{"` + annotationOrphanedAt + `":"` + time.Now().UTC().Format(time.RFC3339) + `"}}}`)
+		if _, err := c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			klog.Errorf("sweeper: flagging orphaned claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			return
+		}
+		klog.Infof("sweeper: flagged orphaned claim %s/%s", pvc.Namespace, pvc.Name)
+		return
+	}
+	if err := c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("sweeper: deleting orphaned claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	klog.Infof("sweeper: deleted orphaned claim %s/%s", pvc.Namespace, pvc.Name)
+}
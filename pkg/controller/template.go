@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+)
+
+// pvcTemplateGVR locates PVCTemplate objects via the dynamic client.
+var pvcTemplateGVR = schema.GroupVersionResource{
+	Group:    v1alpha1.GroupName,
+	Version:  "v1alpha1",
+	Resource: "pvctemplates",
+}
+
+// applyPVCTemplate rebases the claim on the PVCTemplate the volume's template
+// annotation names, if any. The template's spec becomes the base — selectors,
+// dataSources and attributes come through verbatim — while values the pod
+// sets explicitly via annotations keep overriding it. A dangling reference is
+// an error: creating a claim from defaults when the user asked for a template
+// would bind the pod to the wrong storage.
+func (c *Controller) applyPVCTemplate(ctx context.Context, pod *corev1.Pod, volume string, pvc *corev1.PersistentVolumeClaim) error {
+	name := annotations.Get(pod.Annotations, volume, annotations.Template)
+	if name == "" {
+		return nil
+	}
+	if c.dynamic == nil {
+		klog.Warningf("volume %s of pod %s/%s references PVCTemplate %q but no dynamic client is configured, using annotation defaults", volume, pod.Namespace, pod.Name, name)
+		return nil
+	}
+	obj, err := c.dynamic.Resource(pvcTemplateGVR).Namespace(pod.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.recorder.Eventf(pod, corev1.EventTypeWarning, "PVCTemplateNotFound",
+				"volume %q references PVCTemplate %q, which does not exist in namespace %q", volume, name, pod.Namespace)
+			return fmt.Errorf("PVCTemplate %s/%s not found", pod.Namespace, name)
+		}
+		return fmt.Errorf("getting PVCTemplate %s/%s: %v", pod.Namespace, name, err)
+	}
+	tpl := &v1alpha1.PVCTemplate{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, tpl); err != nil {
+		return fmt.Errorf("decoding PVCTemplate %s/%s: %v", pod.Namespace, name, err)
+	}
+
+	// Start from the template, then restore the values the annotations (or
+	// their defaults) resolved where the pod was explicit or the template is
+	// silent.
+	base := pvc.Spec
+	spec := *tpl.Spec.Spec.DeepCopy()
+	if annotations.Get(pod.Annotations, volume, annotations.Size) != "" || specStorageEmpty(&spec) {
+		if spec.Resources.Requests == nil {
+			spec.Resources.Requests = corev1.ResourceList{}
+		}
+		spec.Resources.Requests[corev1.ResourceStorage] = base.Resources.Requests[corev1.ResourceStorage]
+	}
+	if annotations.Get(pod.Annotations, volume, annotations.StorageClass) != "" || spec.StorageClassName == nil {
+		spec.StorageClassName = base.StorageClassName
+	}
+	if annotations.Get(pod.Annotations, volume, annotations.AccessModes) != "" || len(spec.AccessModes) == 0 {
+		spec.AccessModes = base.AccessModes
+	}
+	pvc.Spec = spec
+
+	for k, v := range tpl.Spec.Labels {
+		if pvc.Labels == nil {
+			pvc.Labels = map[string]string{}
+		}
+		if _, ok := pvc.Labels[k]; !ok {
+			pvc.Labels[k] = v
+		}
+	}
+	for k, v := range tpl.Spec.Annotations {
+		if pvc.Annotations == nil {
+			pvc.Annotations = map[string]string{}
+		}
+		if _, ok := pvc.Annotations[k]; !ok {
+			pvc.Annotations[k] = v
+		}
+	}
+	klog.V(2).Infof("built claim %s/%s from PVCTemplate %q for pod %s volume %s", pvc.Namespace, pvc.Name, name, pod.Name, volume)
+	return nil
+}
+
+// specStorageEmpty reports whether a template spec declares no storage
+// request.
+func specStorageEmpty(spec *corev1.PersistentVolumeClaimSpec) bool {
+	q, ok := spec.Resources.Requests[corev1.ResourceStorage]
+	return !ok || q.IsZero()
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// pvcProtectionFinalizer is the finalizer kube-controller-manager places on
+// every in-use claim; it holds deletion until no pod mounts the claim.
+const pvcProtectionFinalizer = "kubernetes.io/pvc-protection"
+
+// sweepStuckTerminating reports managed claims stuck in Terminating behind the
+// pvc-protection finalizer, naming the pods holding them. A claim whose
+// blocking pods are all gone is stale — the protection controller missed the
+// pod deletion — and is force-released when that is enabled.
+func (c *Controller) sweepStuckTerminating(ctx context.Context) {
+	if c.cfg.StuckTerminatingThreshold <= 0 {
+		return
+	}
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("sweeper: listing managed claims: %v", err)
+		return
+	}
+
+	metrics.StuckTerminatingClaims.Reset()
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if pvc.DeletionTimestamp == nil || !hasFinalizer(pvc.Finalizers, pvcProtectionFinalizer) {
+			continue
+		}
+		if time.Since(pvc.DeletionTimestamp.Time) < c.cfg.StuckTerminatingThreshold {
+			continue
+		}
+		blockers, err := c.claimBlockers(ctx, pvc)
+		if err != nil {
+			klog.Errorf("sweeper: finding pods blocking claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			continue
+		}
+		metrics.StuckTerminatingClaims.WithLabelValues(pvc.Namespace, pvc.Name).Set(time.Since(pvc.DeletionTimestamp.Time).Seconds())
+		if len(blockers) > 0 {
+			klog.Warningf("claim %s/%s has been Terminating for %s, blocked by pod(s) %s",
+				pvc.Namespace, pvc.Name, time.Since(pvc.DeletionTimestamp.Time).Round(time.Second), strings.Join(blockers, ", "))
+			c.recorder.Eventf(pvc, corev1.EventTypeWarning, "StuckTerminating",
+				"claim has been Terminating for %s; deletion is blocked by pod(s) %s still mounting it",
+				time.Since(pvc.DeletionTimestamp.Time).Round(time.Second), strings.Join(blockers, ", "))
+			continue
+		}
+		// No pod references the claim anymore, so pvc-protection should have
+		// let go; the reference it is waiting on is stale.
+		if !c.cfg.ForceReleaseStuckClaims {
+			c.recorder.Eventf(pvc, corev1.EventTypeWarning, "StuckTerminating",
+				"claim has been Terminating for %s with no pod mounting it; enable --force-release-stuck-claims to remove the stale %s finalizer",
+				time.Since(pvc.DeletionTimestamp.Time).Round(time.Second), pvcProtectionFinalizer)
+			continue
+		}
+		if err := c.removeProtectionFinalizer(ctx, pvc); err != nil {
+			klog.Errorf("sweeper: force-releasing claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			continue
+		}
+		klog.Infof("sweeper: force-released claim %s/%s stuck Terminating with no referencing pod", pvc.Namespace, pvc.Name)
+		c.recorder.Eventf(pvc, corev1.EventTypeNormal, "ForceReleased",
+			"removed the stale %s finalizer; no pod mounts the claim", pvcProtectionFinalizer)
+	}
+}
+
+// claimBlockers returns namespace/name of every pod mounting the claim. The
+// protection controller also waits on terminating pods, so those count too.
+func (c *Controller) claimBlockers(ctx context.Context, pvc *corev1.PersistentVolumeClaim) ([]string, error) {
+	pods, err := c.client.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var blockers []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvc.Name {
+				blockers = append(blockers, pod.Namespace+"/"+pod.Name)
+				break
+			}
+		}
+	}
+	return blockers, nil
+}
+
+// removeProtectionFinalizer removes the pvc-protection finalizer with a
+// test-guarded JSON patch, so a concurrent finalizer change aborts the patch
+// instead of removing the wrong entry.
+func (c *Controller) removeProtectionFinalizer(ctx context.Context, pvc *corev1.PersistentVolumeClaim) error {
+	for i, f := range pvc.Finalizers {
+		if f != pvcProtectionFinalizer {
+			continue
+		}
+		patch := []byte(fmt.Sprintf(
+			`[{"op":"test","path":"/metadata/finalizers/%d","value":%q},{"op":"remove","path":"/metadata/finalizers/%d"}]`, i, f, i))
+		_, err := c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.JSONPatchType, patch, metav1.PatchOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
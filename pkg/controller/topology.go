@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+const (
+	// annotationSelectedNode is the upstream annotation local-volume and
+	// topology-aware provisioners use to pick a node for provisioning.
+	annotationSelectedNode = "volume.kubernetes.io/selected-node"
+
+	// labelTopologyZone is the standard zone label mirrored from the node
+	// onto the claim for zonal provisioners and operators.
+	labelTopologyZone = "topology.kubernetes.io/zone"
+)
+
+// pinClaimTopology copies topology hints from the pod's scheduled node onto
+// the claim, so local and zonal provisioners place the volume where the pod
+// already runs. Volumes opt in via the .topology-pin annotation.
+func (c *Controller) pinClaimTopology(ctx context.Context, pod *corev1.Pod, volume, claim string) {
+	if annotations.Get(pod.Annotations, volume, annotations.TopologyPin) != "true" || pod.Spec.NodeName == "" {
+		return
+	}
+
+	pvc, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		klog.V(2).Infof("topology pin: getting claim %s/%s: %v", pod.Namespace, claim, err)
+		return
+	}
+	if pvc.Status.Phase == corev1.ClaimBound || pvc.Annotations[annotationSelectedNode] == pod.Spec.NodeName {
+		return
+	}
+
+	anns := map[string]string{annotationSelectedNode: pod.Spec.NodeName}
+	labels := map[string]string{}
+	node, err := c.client.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, metav1.GetOptions{})
+	if err != nil {
+		klog.V(2).Infof("topology pin: getting node %s: %v", pod.Spec.NodeName, err)
+	} else if zone := node.Labels[labelTopologyZone]; zone != "" {
+		labels[labelTopologyZone] = zone
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": anns,
+			"labels":      labels,
+		},
+	})
+	if err != nil {
+		klog.Errorf("topology pin: encoding patch for claim %s/%s: %v", pod.Namespace, claim, err)
+		return
+	}
+	if _, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Patch(ctx, claim, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.Errorf("topology pin: patching claim %s/%s: %v", pod.Namespace, claim, err)
+		return
+	}
+	klog.Infof("topology pin: pinned claim %s/%s to node %s", pod.Namespace, claim, pod.Spec.NodeName)
+	c.recorder.Eventf(pod, corev1.EventTypeNormal, "ClaimTopologyPinned",
+		"pinned PersistentVolumeClaim %q to node %q", claim, pod.Spec.NodeName)
+}
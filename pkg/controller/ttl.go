@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// TTL-based garbage collection: a claim whose volume carried a
+// ttlAfterPodDeletion annotation survives its pod by that duration, giving
+// users a window to recover data from crashed pods. The pod deletion time is
+// stamped on the claim when the pod watch sees the delete, and the sweeper
+// removes the claim once the TTL has elapsed.
+const (
+	// annotationTTLAfterPodDeletion carries the TTL copied from the pod's
+	// volume annotation onto the claim.
+	annotationTTLAfterPodDeletion = "pvc-webhook/ttl-after-pod-deletion"
+	// annotationPodDeletedAt records when the referencing pod was deleted.
+	annotationPodDeletedAt = "pvc-webhook/pod-deleted-at"
+)
+
+// handlePodDeleted stamps the deletion time on every TTL-carrying claim the
+// deleted pod referenced.
+func (c *Controller) handlePodDeleted(ctx context.Context, pod *corev1.Pod) {
+	for _, claim := range annotations.Claims(pod.Annotations) {
+		pvc, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				klog.Errorf("ttl: getting claim %s/%s: %v", pod.Namespace, claim, err)
+			}
+			continue
+		}
+		if pvc.Annotations[annotationTTLAfterPodDeletion] == "" || pvc.Annotations[annotationPodDeletedAt] != "" {
+			continue
+		}
+		patch := []byte(`{"metadata":{"annotations":{"` + annotationPodDeletedAt + `":"` + time.Now().UTC().Format(time.RFC3339) + `"}}}`)
+		if _, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Patch(ctx, claim, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			klog.Errorf("ttl: stamping pod deletion on claim %s/%s: %v", pod.Namespace, claim, err)
+			continue
+		}
+		klog.Infof("ttl: claim %s/%s enters retention window after deletion of pod %s", pod.Namespace, claim, pod.Name)
+	}
+}
+
+// claimTTLGoverned reports whether a claim is under TTL retention and so must
+// be left alone by the orphan sweeper.
+func claimTTLGoverned(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.Annotations[annotationTTLAfterPodDeletion] != ""
+}
+
+// sweepExpiredTTLs deletes claims whose retention window has elapsed. It runs
+// on the orphan sweeper's interval.
+func (c *Controller) sweepExpiredTTLs(ctx context.Context) {
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("ttl: listing managed claims: %v", err)
+		return
+	}
+	now := time.Now()
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		ttlValue := pvc.Annotations[annotationTTLAfterPodDeletion]
+		deletedValue := pvc.Annotations[annotationPodDeletedAt]
+		if ttlValue == "" || deletedValue == "" {
+			continue
+		}
+		ttl, err := time.ParseDuration(ttlValue)
+		if err != nil {
+			klog.Warningf("ttl: claim %s/%s carries invalid TTL %q, skipping", pvc.Namespace, pvc.Name, ttlValue)
+			continue
+		}
+		deletedAt, err := time.Parse(time.RFC3339, deletedValue)
+		if err != nil {
+			klog.Warningf("ttl: claim %s/%s carries invalid deletion time %q, skipping", pvc.Namespace, pvc.Name, deletedValue)
+			continue
+		}
+		if now.Sub(deletedAt) < ttl {
+			continue
+		}
+		if err := c.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(ctx, pvc.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			klog.Errorf("ttl: deleting expired claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+			continue
+		}
+		klog.Infof("ttl: deleted claim %s/%s, retention of %s elapsed", pvc.Namespace, pvc.Name, ttlValue)
+	}
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// runUsageExporter republishes kubelet volume stats for managed claims as
+// controller metrics on a fixed interval; a zero interval disables it. The
+// gauges are reset on every pass so deleted claims drop out instead of
+// reporting stale values forever.
+func (c *Controller) runUsageExporter(ctx context.Context) {
+	if c.cfg.UsageMetricsInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.UsageMetricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.exportUsageOnce(ctx)
+		}
+	}
+}
+
+// exportUsageOnce performs one scrape-and-publish pass.
+func (c *Controller) exportUsageOnce(ctx context.Context) {
+	stats := c.scrapeVolumeStats(ctx)
+	if stats == nil {
+		return
+	}
+	claims, err := c.client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		LabelSelector: managedByLabelKey + "=" + managedByLabelValue,
+	})
+	if err != nil {
+		klog.Errorf("usage exporter: listing managed claims: %v", err)
+		return
+	}
+	metrics.VolumeCapacityBytes.Reset()
+	metrics.VolumeUsedBytes.Reset()
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		usage, ok := stats[claim.Namespace+"/"+claim.Name]
+		if !ok {
+			continue
+		}
+		pod := claimPodName(claim.OwnerReferences)
+		metrics.VolumeCapacityBytes.WithLabelValues(claim.Namespace, claim.Name, pod).Set(usage.capacityBytes)
+		metrics.VolumeUsedBytes.WithLabelValues(claim.Namespace, claim.Name, pod).Set(usage.usedBytes)
+	}
+}
+
+// claimPodName returns the name of the pod owning a claim, or "" for claims
+// owned by a Job or carrying no owner (identity and shared claims).
+func claimPodName(refs []metav1.OwnerReference) string {
+	for _, ref := range refs {
+		if ref.Kind == "Pod" && ref.APIVersion == "v1" {
+			return ref.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// vacDiscovery caches whether the cluster serves VolumeAttributesClass, so
+// the feature can be enabled in config without failing on older clusters.
+var vacDiscovery struct {
+	once      sync.Once
+	supported bool
+}
+
+// volumeAttributesClassSupported reports whether the VolumeAttributesClass
+// API is served by this cluster. The check runs once and is cached for the
+// process lifetime.
+func (c *Controller) volumeAttributesClassSupported() bool {
+	vacDiscovery.once.Do(func() {
+		for _, gv := range []string{"storage.k8s.io/v1beta1", "storage.k8s.io/v1alpha1"} {
+			resources, err := c.client.Discovery().ServerResourcesForGroupVersion(gv)
+			if err != nil {
+				continue
+			}
+			for _, r := range resources.APIResources {
+				if r.Name == "volumeattributesclasses" {
+					vacDiscovery.supported = true
+					klog.Infof("VolumeAttributesClass API available via %s", gv)
+					return
+				}
+			}
+		}
+		klog.Info("VolumeAttributesClass API not available on this cluster")
+	})
+	return vacDiscovery.supported
+}
+
+// rwopDiscovery caches whether the cluster is new enough for the
+// ReadWriteOncePod access mode (beta since 1.27, GA in 1.29).
+var rwopDiscovery struct {
+	once      sync.Once
+	supported bool
+}
+
+// readWriteOncePodSupported reports whether ReadWriteOncePod can be used as
+// a default; on older clusters callers fall back to ReadWriteOnce.
+func (c *Controller) readWriteOncePodSupported() bool {
+	rwopDiscovery.once.Do(func() {
+		version, err := c.client.Discovery().ServerVersion()
+		if err != nil {
+			klog.Warningf("checking server version for ReadWriteOncePod support: %v", err)
+			return
+		}
+		var major, minor int
+		if _, err := fmt.Sscanf(version.Major, "%d", &major); err != nil {
+			return
+		}
+		if _, err := fmt.Sscanf(version.Minor, "%d", &minor); err != nil {
+			return
+		}
+		rwopDiscovery.supported = major > 1 || (major == 1 && minor >= 27)
+	})
+	return rwopDiscovery.supported
+}
+
+// reconcileVolumeAttributes triggers online volume modification when a pod's
+// volumeAttributesClass annotation no longer matches the existing claim
+// (e.g. an IOPS tier change), and surfaces modification failures as events.
+func (c *Controller) reconcileVolumeAttributes(ctx context.Context, pod *corev1.Pod, volume, claim string) {
+	want := annotations.Get(pod.Annotations, volume, annotations.VolumeAttributesClass)
+	if want == "" || !c.cfg.EnableVolumeAttributesClasses || !c.volumeAttributesClassSupported() {
+		return
+	}
+	pvc, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+
+	if pvc.Spec.VolumeAttributesClassName == nil || *pvc.Spec.VolumeAttributesClassName != want {
+		patch := []byte(fmt.Sprintf(`{"spec":{"volumeAttributesClassName":%q}}`, want))
+		if _, err := c.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Patch(ctx, claim, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			klog.Errorf("requesting volume modification for claim %s/%s: %v", pod.Namespace, claim, err)
+			c.recorder.Eventf(pod, corev1.EventTypeWarning, "VolumeModificationFailed",
+				"could not request volumeAttributesClass %q for claim %q: %v", want, claim, err)
+			return
+		}
+		klog.Infof("requested volumeAttributesClass %q for claim %s/%s", want, pod.Namespace, claim)
+		c.recorder.Eventf(pod, corev1.EventTypeNormal, "VolumeModificationRequested",
+			"requested volumeAttributesClass %q for claim %q", want, claim)
+		return
+	}
+
+	// The modification was already requested; surface a failing
+	// modifyVolumeStatus so users are not left guessing.
+	if mvs := pvc.Status.ModifyVolumeStatus; mvs != nil && mvs.Status == corev1.PersistentVolumeClaimModifyVolumeInfeasible {
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, "VolumeModificationFailed",
+			"modification of claim %q to volumeAttributesClass %q is infeasible", claim, mvs.TargetVolumeAttributesClassName)
+	}
+}
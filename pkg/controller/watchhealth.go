@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// watchStallThreshold is how long the pod watch may go without delivering an
+// event or bookmark before WatchHealthy reports it stalled. Bookmarks arrive
+// well within this window on a healthy connection, so silence this long means
+// the watch is wedged even if the channel has not closed.
+const watchStallThreshold = 5 * time.Minute
+
+// markWatchAlive records that the pod watch just made progress.
+func (c *Controller) markWatchAlive() {
+	c.watchAliveMu.Lock()
+	c.watchAlive = time.Now()
+	c.watchAliveMu.Unlock()
+}
+
+// WatchHealthy returns nil while the pod watch is making progress and an
+// error once it has been silent past the stall threshold. It backs the
+// controller's liveness probe so a wedged watch gets the process restarted
+// rather than silently dropping events.
+func (c *Controller) WatchHealthy() error {
+	c.watchAliveMu.Lock()
+	last := c.watchAlive
+	c.watchAliveMu.Unlock()
+	if last.IsZero() {
+		return nil
+	}
+	if silent := time.Since(last); silent > watchStallThreshold {
+		return fmt.Errorf("pod watch has delivered nothing for %s", silent.Round(time.Second))
+	}
+	return nil
+}
+
+// watchBackoff sleeps for exponentially growing, jittered intervals between
+// failed watch attempts so a flapping API server is not hammered in lockstep
+// by every controller replica at once.
+type watchBackoff struct {
+	delay time.Duration
+}
+
+func newWatchBackoff() *watchBackoff {
+	return &watchBackoff{delay: time.Second}
+}
+
+// sleep waits for the current delay plus up to 50% jitter, then doubles the
+// delay up to a 30 second cap. It returns early when the context ends.
+func (b *watchBackoff) sleep(ctx context.Context) {
+	d := b.delay + time.Duration(rand.Int63n(int64(b.delay)/2+1))
+	if b.delay *= 2; b.delay > 30*time.Second {
+		b.delay = 30 * time.Second
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// reset restores the initial delay after a successful watch.
+func (b *watchBackoff) reset() {
+	b.delay = time.Second
+}
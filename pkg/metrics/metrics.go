@@ -0,0 +1,130 @@
+// Package metrics holds the Prometheus collectors shared by the pvc-webhook
+// binaries.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// BindLatency tracks the time from managed claim creation to phase Bound,
+// labeled by storage class so provisioner performance can be compared.
+var BindLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "pvc_webhook_bind_latency_seconds",
+	Help:    "Seconds from managed PVC creation until the claim reports Bound.",
+	Buckets: prometheus.ExponentialBuckets(0.25, 2, 12),
+}, []string{"storage_class"})
+
+// VolumeCapacityBytes and VolumeUsedBytes mirror the kubelet volume stats for
+// managed claims, joined with the owning pod so dashboards of converted-volume
+// consumption need no separate exporter.
+var (
+	VolumeCapacityBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pvc_webhook_volume_capacity_bytes",
+		Help: "Filesystem capacity of a managed PVC in bytes.",
+	}, []string{"namespace", "persistentvolumeclaim", "pod"})
+	VolumeUsedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pvc_webhook_volume_used_bytes",
+		Help: "Filesystem usage of a managed PVC in bytes.",
+	}, []string{"namespace", "persistentvolumeclaim", "pod"})
+)
+
+// BuildInfo exposes the binary's build metadata as a constant gauge, the
+// conventional way to join it onto other series.
+var BuildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pvc_webhook_build_info",
+	Help: "Build information; the value is always 1.",
+}, []string{"version", "git_sha", "build_date"})
+
+// PVCPendingSeconds tracks how long each managed claim has been Pending, so
+// alerts can fire on stuck provisioning.
+var PVCPendingSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pvc_webhook_pvc_pending_seconds",
+	Help: "Seconds a managed PVC has spent in phase Pending.",
+}, []string{"namespace", "persistentvolumeclaim"})
+
+// DeletedStorageClassClaims counts managed claims still referencing a deleted
+// StorageClass, so operators can alert before new pods fail to provision.
+var DeletedStorageClassClaims = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pvc_webhook_deleted_storageclass_claims",
+	Help: "Managed PVCs referencing a StorageClass that has been deleted.",
+}, []string{"storage_class"})
+
+// StuckTerminatingClaims tracks how long each managed claim has been stuck in
+// Terminating behind the pvc-protection finalizer.
+var StuckTerminatingClaims = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pvc_webhook_pvc_stuck_terminating_seconds",
+	Help: "Seconds a managed PVC has spent Terminating behind the pvc-protection finalizer.",
+}, []string{"namespace", "persistentvolumeclaim"})
+
+// ReviewsThrottled counts admission requests rejected because no review slot
+// freed up within the wait budget, so operators can size the concurrency
+// limit against real bursts.
+var ReviewsThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "pvc_webhook_reviews_throttled_total",
+	Help: "Admission requests rejected at the webhook's concurrency limit.",
+})
+
+// Per-namespace admission outcome counters, for chargeback and rollout
+// dashboards. Label values go through NamespaceLabel so an unbounded number
+// of namespaces cannot blow up series cardinality.
+var (
+	Conversions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pvc_webhook_conversions_total",
+		Help: "Admission reviews that patched the object, by namespace.",
+	}, []string{"namespace"})
+	ConversionSkips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pvc_webhook_conversion_skips_total",
+		Help: "Admission reviews allowed without modification, by namespace.",
+	}, []string{"namespace"})
+	ConversionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pvc_webhook_conversion_failures_total",
+		Help: "Admission reviews denied, by namespace.",
+	}, []string{"namespace"})
+)
+
+var (
+	nsLabelMu    sync.Mutex
+	nsLabelSeen  map[string]bool
+	nsLabelLimit int
+)
+
+// SetNamespaceCardinalityLimit caps how many distinct namespaces the
+// per-namespace counters track; further namespaces are folded into "other".
+// Zero (the default) tracks every namespace.
+func SetNamespaceCardinalityLimit(n int) {
+	nsLabelMu.Lock()
+	nsLabelLimit = n
+	nsLabelMu.Unlock()
+}
+
+// NamespaceLabel returns the namespace label value to use for a namespace,
+// honoring the cardinality limit. Namespaces seen before the limit was
+// reached keep their own series.
+func NamespaceLabel(namespace string) string {
+	nsLabelMu.Lock()
+	defer nsLabelMu.Unlock()
+	if nsLabelLimit <= 0 || nsLabelSeen[namespace] {
+		return namespace
+	}
+	if len(nsLabelSeen) >= nsLabelLimit {
+		return "other"
+	}
+	if nsLabelSeen == nil {
+		nsLabelSeen = map[string]bool{}
+	}
+	nsLabelSeen[namespace] = true
+	return namespace
+}
+
+func init() {
+	prometheus.MustRegister(BindLatency, VolumeCapacityBytes, VolumeUsedBytes, DeletedStorageClassClaims, PVCPendingSeconds, BuildInfo, ReviewsThrottled, StuckTerminatingClaims, Conversions, ConversionSkips, ConversionFailures)
+}
+
+// Handler returns the HTTP handler serving the process's metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
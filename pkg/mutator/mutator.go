@@ -0,0 +1,57 @@
+// Package mutator exposes the emptyDir-to-PVC conversion logic as a plain
+// library, so other admission frameworks, custom controllers, and tests can
+// reuse the policy without running the webhook's HTTP server.
+package mutator
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/webhook"
+)
+
+// Config carries the conversion policy. It is the webhook server's own
+// configuration type, so library callers and the served webhook cannot drift
+// apart. HTTP-serving knobs (kill switch, canary percentages, shadow mode)
+// have no effect here.
+type Config = webhook.Config
+
+// ConvertedVolume names one volume the mutation rewrote and the claim it now
+// references.
+type ConvertedVolume = webhook.ConvertedVolume
+
+// Result reports what Mutate decided beyond the raw patch.
+type Result struct {
+	// Converted lists the volumes rewritten into claim references, in the
+	// order they appear in the pod spec. Empty means the pod was left alone.
+	Converted []ConvertedVolume
+}
+
+// Mutate evaluates the conversion policy against the pod and returns the
+// JSON Patch operations that would rewrite its eligible emptyDir volumes. The
+// pod itself is not modified; callers apply the patch through whatever
+// framework they embed this in. A nil operation slice means no mutation is
+// needed.
+func Mutate(pod *corev1.Pod, cfg Config) ([]jsonpatch.Operation, Result, error) {
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		return nil, Result{}, fmt.Errorf("encoding pod: %v", err)
+	}
+
+	patch, converted, err := webhook.NewServer(cfg).EvaluatePod(raw)
+	if err != nil {
+		return nil, Result{}, err
+	}
+	if len(patch) == 0 {
+		return nil, Result{}, nil
+	}
+
+	var ops []jsonpatch.Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, Result{}, fmt.Errorf("decoding patch: %v", err)
+	}
+	return ops, Result{Converted: converted}, nil
+}
@@ -0,0 +1,98 @@
+// Package secrets reads sensitive configuration from a mounted Secret
+// directory, one value per file named after the setting (the shape a standard
+// Secret volume mount produces). Values there take precedence over environment
+// variables, so credentials and endpoint URLs never have to pass through the
+// pod spec, and the store re-reads the directory so rotated Secrets are picked
+// up without a restart.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Store holds the values read from a mounted Secret directory.
+type Store struct {
+	dir string
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewStore reads the directory once and returns the store, or an error when
+// the directory cannot be read. A missing directory is an error: a configured
+// secrets mount that is absent means the deployment is broken.
+func NewStore(dir string) (*Store, error) {
+	s := &Store{dir: dir}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the value for a key, or "" when the key is absent.
+func (s *Store) Get(key string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+// Watch re-reads the directory on the given interval until the context ends,
+// so rotated Secret contents take effect for settings read at request time.
+// Kubelet updates Secret mounts by atomic symlink swap, so polling observes
+// complete states only.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.load(); err != nil {
+				klog.Errorf("reloading secrets from %s: %v", s.dir, err)
+			}
+		}
+	}
+}
+
+// load reads every regular file in the directory into the value map, skipping
+// the dot-prefixed bookkeeping entries of the kubelet's atomic-update scheme.
+func (s *Store) load() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("reading secrets directory: %v", err)
+	}
+	values := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("reading secret %s: %v", entry.Name(), err)
+		}
+		values[entry.Name()] = strings.TrimSpace(string(data))
+	}
+
+	s.mu.Lock()
+	changed := len(values) != len(s.values)
+	for k, v := range values {
+		if s.values[k] != v {
+			changed = true
+		}
+	}
+	s.values = values
+	s.mu.Unlock()
+	if changed {
+		klog.Infof("loaded %d secret value(s) from %s", len(values), s.dir)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Package version carries the build metadata stamped into the binaries at
+// build time, e.g.
+//
+//	go build -ldflags "\
+//	  -X github.com/rkitindi-kr/pvc-webhook/pkg/version.Version=v1.4.0 \
+//	  -X github.com/rkitindi-kr/pvc-webhook/pkg/version.GitSHA=$(git rev-parse --short HEAD) \
+//	  -X github.com/rkitindi-kr/pvc-webhook/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "fmt"
+
+// Overridden at build time via -ldflags; the defaults identify local builds.
+var (
+	Version   = "dev"
+	GitSHA    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info is the JSON shape served by the /version endpoints.
+type Info struct {
+	Version   string `json:"version"`
+	GitSHA    string `json:"gitSHA"`
+	BuildDate string `json:"buildDate"`
+}
+
+// Get returns the build metadata of the running binary.
+func Get() Info {
+	return Info{Version: Version, GitSHA: GitSHA, BuildDate: BuildDate}
+}
+
+// String returns a human-readable one-liner for --version output.
+func String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", Version, GitSHA, BuildDate)
+}
+
+// Short identifies the release compactly, for annotations and labels: the
+// version plus the commit when one is known.
+func Short() string {
+	if GitSHA == "unknown" {
+		return Version
+	}
+	return Version + "+" + GitSHA
+}
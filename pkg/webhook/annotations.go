@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// The annotation schema is owned by pkg/annotations so the webhook and the
+// controller cannot drift apart; these aliases keep call sites short.
+const (
+	paramConverted    = annotations.Converted
+	paramClaim        = annotations.Claim
+	paramSize         = annotations.Size
+	paramStorageClass = annotations.StorageClass
+	paramAccessModes  = annotations.AccessModes
+	paramIdentity     = annotations.Identity
+	paramShared       = annotations.Shared
+	paramSelector     = annotations.Selector
+	paramVolumeMode   = annotations.VolumeMode
+)
+
+// volumeAnnotation returns the per-volume annotation key for a parameter,
+// e.g. volumeAnnotation("cache", "size") == "pvc-webhook.vol/cache.size".
+func volumeAnnotation(volume, param string) string {
+	return annotations.Key(volume, param)
+}
+
+// parseSizeAnnotation parses a size annotation with the shared friendly
+// parser, accepting spellings like "10 GiB" alongside canonical quantities.
+func parseSizeAnnotation(value string) (resource.Quantity, error) {
+	return annotations.ParseSize(value)
+}
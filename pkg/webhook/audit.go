@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/version"
+)
+
+// conversionRecordGVR locates ConversionRecord objects via the dynamic client.
+var conversionRecordGVR = schema.GroupVersionResource{
+	Group:    v1alpha1.GroupName,
+	Version:  "v1alpha1",
+	Resource: "conversionrecords",
+}
+
+// writeConversionRecords persists one ConversionRecord per converted volume,
+// giving auditors a queryable in-cluster trail of what was mutated, into
+// what, and on whose request. Events cover the same ground but age out of
+// etcd within an hour; records live until the controller's TTL sweep removes
+// them. Failures are logged and never block admission: an audit gap is
+// preferable to refusing pods.
+func (s *Server) writeConversionRecords(req *admissionv1.AdmissionRequest, pod *admissionPod, converted []convertedVolume) {
+	ctx := context.Background()
+	for _, cv := range converted {
+		record := s.conversionRecord(req, pod, cv)
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(record)
+		if err != nil {
+			klog.Warningf("encoding ConversionRecord for volume %q of %s/%s: %v", cv.volume, req.Namespace, podName(pod), err)
+			continue
+		}
+		_, err = s.dynamic.Resource(conversionRecordGVR).Namespace(req.Namespace).Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+		if err != nil {
+			klog.Warningf("writing ConversionRecord for volume %q of %s/%s (CRD missing or inaccessible?): %v", cv.volume, req.Namespace, podName(pod), err)
+		}
+	}
+}
+
+// conversionRecord builds the audit record for one converted volume. The
+// parameters snapshot the effective values: the pod's annotations where set,
+// the webhook defaults otherwise, matching what claimObject would create.
+func (s *Server) conversionRecord(req *admissionv1.AdmissionRequest, pod *admissionPod, cv convertedVolume) *v1alpha1.ConversionRecord {
+	size := annotations.Get(pod.Annotations, cv.volume, annotations.Size)
+	if size == "" {
+		size = s.cfg.DefaultSize
+	}
+	class := annotations.Get(pod.Annotations, cv.volume, annotations.StorageClass)
+	if class == "" {
+		class = s.cfg.DefaultStorageClass
+	}
+	modes := annotations.Get(pod.Annotations, cv.volume, annotations.AccessModes)
+	if modes == "" {
+		modes = strings.Join(s.cfg.DefaultAccessModes, ",")
+	}
+	params := map[string]string{annotations.Size: size}
+	if class != "" {
+		params[annotations.StorageClass] = class
+	}
+	if modes != "" {
+		params[annotations.AccessModes] = modes
+	}
+	return &v1alpha1.ConversionRecord{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "ConversionRecord",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: truncateName(podName(pod)+"-"+cv.volume) + "-",
+			Namespace:    req.Namespace,
+			Labels:       map[string]string{"created-by": "pvc-webhook"},
+		},
+		Spec: v1alpha1.ConversionRecordSpec{
+			PodName:        podName(pod),
+			PodUID:         string(pod.UID),
+			Volume:         cv.volume,
+			VolumeSource:   "emptyDir",
+			ClaimName:      cv.claim,
+			Parameters:     params,
+			RequestUID:     string(req.UID),
+			Username:       req.UserInfo.Username,
+			WebhookVersion: version.Short(),
+			ConvertedAt:    metav1.NewTime(time.Now().UTC()),
+		},
+	}
+}
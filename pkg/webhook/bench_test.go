@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// benchmarkReview builds an AdmissionReview for a pod with the given number of
+// emptyDir volumes and env-var-heavy containers, approximating the large specs
+// the webhook sees in real clusters.
+func benchmarkReview(b *testing.B, volumes, containers int) []byte {
+	pod := corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bench-pod",
+			Namespace: "default",
+			UID:       "6f1cbd51-3326-4ab5-ae69-3d5372a3b8f1",
+		},
+	}
+	for i := 0; i < volumes; i++ {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name:         fmt.Sprintf("scratch-%d", i),
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+	for i := 0; i < containers; i++ {
+		container := corev1.Container{Name: fmt.Sprintf("app-%d", i), Image: "busybox"}
+		for j := 0; j < 50; j++ {
+			container.Env = append(container.Env, corev1.EnvVar{
+				Name:  fmt.Sprintf("ENV_%d_%d", i, j),
+				Value: "value",
+			})
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, container)
+	}
+	raw, err := json.Marshal(&pod)
+	if err != nil {
+		b.Fatalf("encoding pod: %v", err)
+	}
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       "8d8bfd6e-51c4-4ea4-9a02-8a8a26acae2e",
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, err := json.Marshal(&review)
+	if err != nil {
+		b.Fatalf("encoding review: %v", err)
+	}
+	return body
+}
+
+func benchmarkServer() *Server {
+	return NewServer(Config{
+		DefaultSize:          "2Gi",
+		ConversionPercentage: 100,
+	})
+}
+
+func BenchmarkReviewSmallPod(b *testing.B) {
+	s := benchmarkServer()
+	body := benchmarkReview(b, 2, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.review(body); err != nil {
+			b.Fatalf("review: %v", err)
+		}
+	}
+}
+
+func BenchmarkReviewLargePod(b *testing.B) {
+	s := benchmarkServer()
+	body := benchmarkReview(b, 8, 20)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.review(body); err != nil {
+			b.Fatalf("review: %v", err)
+		}
+	}
+}
+
+func BenchmarkReviewNoEmptyDirs(b *testing.B) {
+	s := benchmarkServer()
+	body := benchmarkReview(b, 0, 20)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.review(body); err != nil {
+			b.Fatalf("review: %v", err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"hash/fnv"
+
+	"k8s.io/klog/v2"
+)
+
+// canarySelected reports whether the object identified by key (its UID, or
+// namespace/name before a UID exists) falls inside the configured conversion
+// percentage. The decision is a pure function of the key, so the same object
+// is always treated the same way across webhook replicas and reinvocations.
+func (s *Server) canarySelected(namespace, key string) bool {
+	pct := s.cfg.ConversionPercentage
+	if nsPct, ok := s.cfg.NamespacePercentages[namespace]; ok {
+		pct = nsPct
+	}
+	if pct >= 100 {
+		return true
+	}
+	if pct <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	selected := h.Sum32()%100 < uint32(pct)
+	if !selected {
+		klog.V(2).Infof("canary: %s in %s outside %d%% rollout, not converting", key, namespace, pct)
+	}
+	return selected
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import "fmt"
+
+// A pod spec listing dozens of emptyDir volumes would fan out into as many
+// PVCs; the per-pod cap keeps one misbehaving spec from flooding the
+// provisioner. Volumes are counted in spec order, so which ones convert is
+// deterministic.
+
+// capConvertedVolumes leaves every converted volume beyond the per-pod limit
+// as emptyDir by clearing its source in the in-memory pod — the same veto
+// mechanism policy adjustments use — and returns the warnings to surface.
+// The caller rebuilds the patch afterwards.
+func capConvertedVolumes(pod *admissionPod, converted []convertedVolume, max int) []string {
+	drop := map[string]bool{}
+	for _, cv := range converted[max:] {
+		drop[cv.volume] = true
+	}
+	var warnings []string
+	for i := range pod.Spec.Volumes {
+		vol := &pod.Spec.Volumes[i]
+		if vol.EmptyDir == nil || !drop[vol.Name] {
+			continue
+		}
+		vol.EmptyDir = nil
+		warnings = append(warnings, fmt.Sprintf("pvc-webhook: volume %q left as emptyDir: the pod exceeds the limit of %d converted volumes", vol.Name, max))
+	}
+	return warnings
+}
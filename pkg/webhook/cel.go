@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"k8s.io/klog/v2"
+)
+
+// CEL conversion rules let admins compute storage parameters from pod fields
+// declaratively, without running an external policy engine. Each rule has an
+// optional match condition and per-parameter value expressions, evaluated
+// with the admitted pod bound as `pod`, the volume name as `volume` and the
+// namespace as `namespace`. For example:
+//
+//	{"match": "pod.spec.containers[0].name == 'redis'",
+//	 "size": "string(int(pod.spec.containers[0].resources.limits['memory'].replace('Gi','')) * 2) + 'Gi'"}
+//
+// Rules apply in order; a later rule overrides an earlier one's result for
+// the same parameter. Computed values land in the per-volume annotations the
+// patch builder stamps, so the controller sees them like any other override.
+// When a workload template is being mutated, `pod` binds to the workload
+// object instead, so expressions can reach the template via pod.spec.template.
+
+// CELRule computes conversion parameters for matching pods.
+type CELRule struct {
+	// Match is a CEL expression over pod/volume/namespace that must evaluate
+	// to true for the rule to apply. Empty matches everything.
+	Match string `json:"match,omitempty"`
+	// Size, StorageClass and AccessModes are CEL expressions computing the
+	// respective parameter. AccessModes may evaluate to a list of strings or
+	// a comma-separated string. Empty expressions leave the parameter alone.
+	Size         string `json:"size,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+	AccessModes  string `json:"accessModes,omitempty"`
+}
+
+// celProgram is a compiled rule; nil programs mean the expression was absent.
+type celProgram struct {
+	match        cel.Program
+	size         cel.Program
+	storageClass cel.Program
+	accessModes  cel.Program
+}
+
+// compileCELRules compiles the configured rules, dropping (and logging) the
+// malformed ones so one bad rule cannot disable the rest.
+func compileCELRules(rules []CELRule) []celProgram {
+	if len(rules) == 0 {
+		return nil
+	}
+	env, err := cel.NewEnv(
+		cel.Variable("pod", cel.DynType),
+		cel.Variable("volume", cel.StringType),
+		cel.Variable("namespace", cel.StringType),
+	)
+	if err != nil {
+		klog.Errorf("building CEL environment, rules disabled: %v", err)
+		return nil
+	}
+	var compiled []celProgram
+	for i, rule := range rules {
+		prog := celProgram{}
+		ok := true
+		for _, expr := range []struct {
+			src  string
+			dst  *cel.Program
+			what string
+		}{
+			{rule.Match, &prog.match, "match"},
+			{rule.Size, &prog.size, "size"},
+			{rule.StorageClass, &prog.storageClass, "storageClass"},
+			{rule.AccessModes, &prog.accessModes, "accessModes"},
+		} {
+			if expr.src == "" {
+				continue
+			}
+			ast, issues := env.Compile(expr.src)
+			if issues != nil && issues.Err() != nil {
+				klog.Warningf("ignoring CEL rule %d: compiling %s: %v", i, expr.what, issues.Err())
+				ok = false
+				break
+			}
+			p, err := env.Program(ast)
+			if err != nil {
+				klog.Warningf("ignoring CEL rule %d: building %s program: %v", i, expr.what, err)
+				ok = false
+				break
+			}
+			*expr.dst = p
+		}
+		if ok {
+			compiled = append(compiled, prog)
+		}
+	}
+	return compiled
+}
+
+// applyCELRules evaluates the compiled rules against each converted volume,
+// writing computed parameters into the pod's annotations. It reports whether
+// anything changed, in which case the caller rebuilds the patch.
+func (s *Server) applyCELRules(pod *admissionPod, raw []byte, converted []convertedVolume) bool {
+	if len(s.celRules) == 0 || len(converted) == 0 {
+		return false
+	}
+	var podObj map[string]interface{}
+	if err := json.Unmarshal(raw, &podObj); err != nil {
+		klog.Errorf("decoding pod for CEL evaluation: %v", err)
+		return false
+	}
+	changed := false
+	for _, cv := range converted {
+		vars := map[string]interface{}{
+			"pod":       podObj,
+			"volume":    cv.volume,
+			"namespace": pod.Namespace,
+		}
+		for i := range s.celRules {
+			rule := &s.celRules[i]
+			if rule.match != nil {
+				matched, err := evalCELBool(rule.match, vars)
+				if err != nil {
+					klog.Warningf("CEL rule %d: evaluating match for volume %q: %v", i, cv.volume, err)
+					continue
+				}
+				if !matched {
+					continue
+				}
+			}
+			changed = applyCELParam(pod, cv.volume, paramSize, rule.size, i, vars) || changed
+			changed = applyCELParam(pod, cv.volume, paramStorageClass, rule.storageClass, i, vars) || changed
+			changed = applyCELParam(pod, cv.volume, paramAccessModes, rule.accessModes, i, vars) || changed
+		}
+	}
+	return changed
+}
+
+// applyCELParam evaluates one value expression and stamps the result as the
+// volume's annotation. Evaluation failures skip the parameter with a warning.
+func applyCELParam(pod *admissionPod, volume, param string, prog cel.Program, rule int, vars map[string]interface{}) bool {
+	if prog == nil {
+		return false
+	}
+	out, _, err := prog.Eval(vars)
+	if err != nil {
+		klog.Warningf("CEL rule %d: evaluating %s for volume %q: %v", rule, param, volume, err)
+		return false
+	}
+	value, err := celValueString(out)
+	if err != nil {
+		klog.Warningf("CEL rule %d: %s for volume %q: %v", rule, param, volume, err)
+		return false
+	}
+	return setPolicyAnnotation(pod, volume, param, value)
+}
+
+// evalCELBool evaluates a match expression, requiring a boolean result.
+func evalCELBool(prog cel.Program, vars map[string]interface{}) (bool, error) {
+	out, _, err := prog.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("match expression returned %T, want bool", out.Value())
+	}
+	return b, nil
+}
+
+// celValueString renders a CEL result as an annotation value: strings pass
+// through, numbers are formatted, and lists join with commas.
+func celValueString(v ref.Val) (string, error) {
+	switch t := v.Value().(type) {
+	case string:
+		return t, nil
+	case int64:
+		return strconv.FormatInt(t, 10), nil
+	case uint64:
+		return strconv.FormatUint(t, 10), nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case []ref.Val:
+		parts := make([]string, 0, len(t))
+		for _, elem := range t {
+			s, err := celValueString(elem)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	}
+	return "", fmt.Errorf("unsupported result type %T", v.Value())
+}
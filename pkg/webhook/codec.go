@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The API server may send AdmissionReview objects in either admission.k8s.io/v1
+// or the older v1beta1, depending on cluster version and webhook configuration.
+// Requests are normalized to v1 for the mutation logic and the response is
+// encoded in whatever version the request arrived in.
+
+type reviewVersion string
+
+const (
+	reviewV1      reviewVersion = "admission.k8s.io/v1"
+	reviewV1beta1 reviewVersion = "admission.k8s.io/v1beta1"
+)
+
+// review decodes an AdmissionReview in either supported version, runs the
+// mutation, and returns the encoded response in the request's version.
+func (s *Server) review(body []byte) ([]byte, error) {
+	return s.reviewWith(body, s.mutate)
+}
+
+// reviewWith is review with a caller-chosen request handler, shared by the
+// mutation and validation endpoints.
+func (s *Server) reviewWith(body []byte, handle func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) ([]byte, error) {
+	var probe metav1.TypeMeta
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, fmt.Errorf("decoding admission review: %v", err)
+	}
+
+	switch reviewVersion(probe.APIVersion) {
+	case reviewV1:
+		in := admissionv1.AdmissionReview{}
+		if err := json.Unmarshal(body, &in); err != nil {
+			return nil, fmt.Errorf("decoding v1 admission review: %v", err)
+		}
+		if in.Request == nil {
+			return nil, fmt.Errorf("admission review has no request")
+		}
+		out := admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: string(reviewV1), Kind: "AdmissionReview"},
+			Response: handle(in.Request),
+		}
+		out.Response.UID = in.Request.UID
+		return json.Marshal(&out)
+
+	case reviewV1beta1:
+		in := admissionv1beta1.AdmissionReview{}
+		if err := json.Unmarshal(body, &in); err != nil {
+			return nil, fmt.Errorf("decoding v1beta1 admission review: %v", err)
+		}
+		if in.Request == nil {
+			return nil, fmt.Errorf("admission review has no request")
+		}
+		resp := handle(requestFromBeta(in.Request))
+		out := admissionv1beta1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: string(reviewV1beta1), Kind: "AdmissionReview"},
+			Response: responseToBeta(resp),
+		}
+		out.Response.UID = in.Request.UID
+		return json.Marshal(&out)
+
+	default:
+		return nil, fmt.Errorf("unsupported admission review version %q", probe.APIVersion)
+	}
+}
+
+// requestFromBeta converts a v1beta1 request to v1. The two versions are
+// structurally identical for the fields the webhook reads.
+func requestFromBeta(in *admissionv1beta1.AdmissionRequest) *admissionv1.AdmissionRequest {
+	out := &admissionv1.AdmissionRequest{
+		UID:       in.UID,
+		Kind:      in.Kind,
+		Resource:  in.Resource,
+		Name:      in.Name,
+		Namespace: in.Namespace,
+		Operation: admissionv1.Operation(in.Operation),
+		UserInfo:  in.UserInfo,
+		Object:    in.Object,
+		OldObject: in.OldObject,
+	}
+	if in.DryRun != nil {
+		out.DryRun = in.DryRun
+	}
+	return out
+}
+
+// responseToBeta converts a v1 response back to v1beta1 for older clusters.
+func responseToBeta(in *admissionv1.AdmissionResponse) *admissionv1beta1.AdmissionResponse {
+	out := &admissionv1beta1.AdmissionResponse{
+		UID:      in.UID,
+		Allowed:  in.Allowed,
+		Result:   in.Result,
+		Patch:    in.Patch,
+		Warnings: in.Warnings,
+	}
+	if in.PatchType != nil {
+		pt := admissionv1beta1.PatchType(*in.PatchType)
+		out.PatchType = &pt
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+// The pvc-webhook.io CRDs are served in v1alpha1 and v1beta1; the API server
+// calls /convert to translate stored objects into whichever version a client
+// asks for. Conversion works on unstructured objects so unknown fields pass
+// through untouched.
+
+const (
+	crdVersionAlpha = "pvc-webhook.io/v1alpha1"
+	crdVersionBeta  = "pvc-webhook.io/v1beta1"
+)
+
+// serveConvert handles apiextensions.k8s.io/v1 ConversionReview requests.
+func (s *Server) serveConvert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := getBuffer()
+	defer putBuffer(body)
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, fmt.Sprintf("reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	in := apiextensionsv1.ConversionReview{}
+	if err := json.Unmarshal(body.Bytes(), &in); err != nil {
+		http.Error(w, fmt.Sprintf("decoding conversion review: %v", err), http.StatusBadRequest)
+		return
+	}
+	if in.Request == nil {
+		http.Error(w, "conversion review has no request", http.StatusBadRequest)
+		return
+	}
+
+	resp := &apiextensionsv1.ConversionResponse{
+		UID:    in.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range in.Request.Objects {
+		converted, err := convertCustomResource(obj.Raw, in.Request.DesiredAPIVersion)
+		if err != nil {
+			klog.Errorf("converting object to %s: %v", in.Request.DesiredAPIVersion, err)
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			resp.ConvertedObjects = nil
+			break
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	out := apiextensionsv1.ConversionReview{TypeMeta: in.TypeMeta, Response: resp}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&out); err != nil {
+		klog.Errorf("writing conversion response: %v", err)
+	}
+}
+
+// convertCustomResource rewrites one object into the desired API version.
+func convertCustomResource(raw []byte, desired string) ([]byte, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(raw); err != nil {
+		return nil, fmt.Errorf("decoding object: %v", err)
+	}
+	current := obj.GetAPIVersion()
+	if current == desired {
+		return raw, nil
+	}
+
+	switch obj.GetKind() {
+	case "ManagedVolumeClaim":
+		if err := convertManagedVolumeClaim(obj, current, desired); err != nil {
+			return nil, err
+		}
+	case "StorageBudget":
+		// The StorageBudget schema is identical in both versions.
+		if !crdVersionKnown(current) || !crdVersionKnown(desired) {
+			return nil, fmt.Errorf("unsupported conversion from %q to %q", current, desired)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", obj.GetKind())
+	}
+	obj.SetAPIVersion(desired)
+	return obj.MarshalJSON()
+}
+
+// convertManagedVolumeClaim moves the pod identity between the flat v1alpha1
+// fields (spec.podName/podUID) and the nested v1beta1 spec.sourcePod.
+func convertManagedVolumeClaim(obj *unstructured.Unstructured, current, desired string) error {
+	switch {
+	case current == crdVersionAlpha && desired == crdVersionBeta:
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "podName")
+		uid, _, _ := unstructured.NestedString(obj.Object, "spec", "podUID")
+		unstructured.RemoveNestedField(obj.Object, "spec", "podName")
+		unstructured.RemoveNestedField(obj.Object, "spec", "podUID")
+		source := map[string]interface{}{"name": name}
+		if uid != "" {
+			source["uid"] = uid
+		}
+		return unstructured.SetNestedMap(obj.Object, source, "spec", "sourcePod")
+
+	case current == crdVersionBeta && desired == crdVersionAlpha:
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "sourcePod", "name")
+		uid, _, _ := unstructured.NestedString(obj.Object, "spec", "sourcePod", "uid")
+		unstructured.RemoveNestedField(obj.Object, "spec", "sourcePod")
+		if err := unstructured.SetNestedField(obj.Object, name, "spec", "podName"); err != nil {
+			return err
+		}
+		if uid == "" {
+			return nil
+		}
+		return unstructured.SetNestedField(obj.Object, uid, "spec", "podUID")
+	}
+	return fmt.Errorf("unsupported conversion from %q to %q", current, desired)
+}
+
+// crdVersionKnown reports whether the API version is one this server serves.
+func crdVersionKnown(v string) bool {
+	return v == crdVersionAlpha || v == crdVersionBeta
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Synchronous creation: with a client configured and SyncCreate enabled, the
+// webhook creates each converted volume's claim before returning the patch,
+// closing the race where a pod schedules and fails mounting because the
+// controller has not created the claim yet. The webhook writes only the core
+// spec; the controller's server-side apply converges the rest when it sees
+// the pod.
+
+// SetClient enables synchronous claim creation and other cluster-side
+// features that need a clientset.
+func (s *Server) SetClient(client kubernetes.Interface) {
+	s.client = client
+}
+
+// createClaims creates the claims for the converted volumes, tolerating
+// claims that already exist so reinvocation stays idempotent. Failures are
+// logged but do not block admission: the controller creates the claim later,
+// which is no worse than the asynchronous mode.
+func (s *Server) createClaims(namespace string, pod *admissionPod, converted []convertedVolume) {
+	ctx := context.Background()
+	for _, cv := range converted {
+		pvc := s.claimObject(namespace, pod, cv.volume, cv.claim)
+		_, err := s.client.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{})
+		if err != nil && !errors.IsAlreadyExists(err) {
+			klog.Warningf("synchronous create of claim %s/%s failed, leaving it to the controller: %v", namespace, cv.claim, err)
+			continue
+		}
+		if err == nil {
+			klog.InfoS("created claim synchronously", "namespace", namespace, "claim", cv.claim, "volume", cv.volume)
+		}
+	}
+}
+
+// claimObject builds the core claim spec from the pod's annotations and the
+// webhook defaults. It deliberately mirrors the controller's labeling so the
+// controller adopts the claim instead of fighting over it.
+func (s *Server) claimObject(namespace string, pod *admissionPod, volume, claim string) *corev1.PersistentVolumeClaim {
+	size := annotations.Get(pod.Annotations, volume, annotations.Size)
+	if size == "" {
+		size = s.cfg.DefaultSize
+	}
+	qty, err := annotations.ParseSize(size)
+	if err != nil {
+		qty = resource.MustParse(s.cfg.DefaultSize)
+	}
+	modeNames := s.cfg.DefaultAccessModes
+	if v := annotations.Get(pod.Annotations, volume, annotations.AccessModes); v != "" {
+		modeNames = strings.Split(v, ",")
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claim,
+			Namespace: namespace,
+			Labels:    map[string]string{"created-by": "pvc-webhook"},
+			Annotations: map[string]string{
+				"pvc-webhook/source-volume": volume,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessModeList(modeNames),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: qty,
+				},
+			},
+		},
+	}
+	if sc := annotations.Get(pod.Annotations, volume, annotations.StorageClass); sc != "" {
+		pvc.Spec.StorageClassName = &sc
+	} else if s.cfg.DefaultStorageClass != "" {
+		sc := s.cfg.DefaultStorageClass
+		pvc.Spec.StorageClassName = &sc
+	}
+	return pvc
+}
+
+// accessModeList maps access mode names (and their short forms) onto the API
+// constants, defaulting to ReadWriteOnce.
+func accessModeList(names []string) []corev1.PersistentVolumeAccessMode {
+	var modes []corev1.PersistentVolumeAccessMode
+	for _, name := range names {
+		switch strings.TrimSpace(name) {
+		case "ReadWriteOnce", "RWO":
+			modes = append(modes, corev1.ReadWriteOnce)
+		case "ReadOnlyMany", "ROX":
+			modes = append(modes, corev1.ReadOnlyMany)
+		case "ReadWriteMany", "RWX":
+			modes = append(modes, corev1.ReadWriteMany)
+		case "ReadWriteOncePod", "RWOP":
+			modes = append(modes, corev1.ReadWriteOncePod)
+		}
+	}
+	if len(modes) == 0 {
+		modes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return modes
+}
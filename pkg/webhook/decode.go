@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// admissionPod is the subset of a pod the mutation logic actually reads.
+// Decoding into it instead of the full corev1.Pod skips containers, env vars
+// and the rest of the spec, which dominate decode cost on large pods.
+type admissionPod struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              admissionPodSpec `json:"spec"`
+}
+
+// admissionPodSpec carries the spec fields inspected when building patches.
+type admissionPodSpec struct {
+	Volumes         []admissionVolume          `json:"volumes"`
+	Containers      []admissionContainer       `json:"containers"`
+	InitContainers  []admissionContainer       `json:"initContainers"`
+	SchedulingGates []corev1.PodSchedulingGate `json:"schedulingGates"`
+}
+
+// admissionContainer decodes only the image and mount names needed to apply
+// the sidecar exclusion rules.
+type admissionContainer struct {
+	Image        string           `json:"image"`
+	VolumeMounts []admissionMount `json:"volumeMounts"`
+}
+
+// admissionMount is a volume mount reduced to the volume it references.
+type admissionMount struct {
+	Name string `json:"name"`
+}
+
+// admissionVolume decodes only the volume name and the emptyDir source used to
+// decide convertibility; all other volume sources are irrelevant and ignored.
+type admissionVolume struct {
+	Name     string                       `json:"name"`
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+}
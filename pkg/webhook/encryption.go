@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// Encryption policy: namespaces labeled as handling sensitive data may only
+// place volumes on storage classes known to encrypt at rest. A class counts as
+// encrypted when it is named in EncryptedStorageClasses or when its parameters
+// say so (an "encrypted" parameter, or any parameter key containing "encrypt",
+// set to "true"). Violations are rejected at admission with the storage class
+// named in the reason.
+
+// namespaceSensitive reports whether the namespace is labeled as handling
+// sensitive data. The label is configured as "key" (any value but "false"
+// counts) or "key=value" (exact match). Requires the namespace informer.
+func (s *Server) namespaceSensitive(namespace string) bool {
+	if s.cfg.SensitiveNamespaceLabel == "" || s.nsLister == nil {
+		return false
+	}
+	ns, err := s.nsLister.Get(namespace)
+	if err != nil {
+		return false
+	}
+	key, want, exact := strings.Cut(s.cfg.SensitiveNamespaceLabel, "=")
+	got, labeled := ns.Labels[key]
+	if !labeled {
+		return false
+	}
+	if exact {
+		return got == want
+	}
+	return got != "false"
+}
+
+// classEncrypted reports whether a storage class encrypts at rest, by name
+// list first and parameter inspection second. Lookups against the API are
+// cached for the process lifetime; class parameters are immutable.
+func (s *Server) classEncrypted(class string) bool {
+	for _, name := range s.cfg.EncryptedStorageClasses {
+		if class == name {
+			return true
+		}
+	}
+	if s.client == nil {
+		return false
+	}
+	s.encryptedMu.Lock()
+	encrypted, cached := s.encryptedClasses[class]
+	s.encryptedMu.Unlock()
+	if cached {
+		return encrypted
+	}
+	sc, err := s.client.StorageV1().StorageClasses().Get(context.Background(), class, metav1.GetOptions{})
+	if err != nil {
+		klog.V(2).Infof("looking up storage class %q: %v", class, err)
+		return false
+	}
+	for key, value := range sc.Parameters {
+		if strings.Contains(strings.ToLower(key), "encrypt") && value == "true" {
+			encrypted = true
+			break
+		}
+	}
+	s.encryptedMu.Lock()
+	if s.encryptedClasses == nil {
+		s.encryptedClasses = map[string]bool{}
+	}
+	s.encryptedClasses[class] = encrypted
+	s.encryptedMu.Unlock()
+	return encrypted
+}
+
+// enforceEncryption checks every converted volume's effective storage class
+// against the encryption policy, returning a deny error for the first
+// violation. An empty class means the cluster default, which cannot be
+// verified and is rejected in sensitive namespaces.
+func (s *Server) enforceEncryption(namespace string, pod *admissionPod, converted []convertedVolume) error {
+	if !s.namespaceSensitive(namespace) {
+		return nil
+	}
+	nsClass := s.namespaceStorageClass(namespace)
+	for _, cv := range converted {
+		class := pod.Annotations[volumeAnnotation(cv.volume, paramStorageClass)]
+		if class == "" {
+			class = nsClass
+		}
+		if class == "" {
+			class = s.cfg.DefaultStorageClass
+		}
+		if class == "" {
+			return denyf(ReasonForbiddenStorageClass, 403,
+				"namespace %q handles sensitive data; volume %q must name an encrypted storage class explicitly", namespace, cv.volume)
+		}
+		if !s.classEncrypted(class) {
+			return denyf(ReasonForbiddenStorageClass, 403,
+				"namespace %q handles sensitive data; volume %q would use storage class %q, which is not marked as encrypted", namespace, cv.volume, class)
+		}
+	}
+	return nil
+}
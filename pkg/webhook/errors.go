@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The error taxonomy gives denials a machine-readable reason and HTTP-style
+// code in Result, so client tooling and tests can distinguish failure causes
+// without parsing free-form messages. Client input errors carry 4xx codes;
+// only genuine webhook failures report 500.
+const (
+	// ReasonInvalidSize marks an unparsable size annotation.
+	ReasonInvalidSize metav1.StatusReason = "InvalidSize"
+	// ReasonInvalidAccessMode marks an unknown access mode annotation.
+	ReasonInvalidAccessMode metav1.StatusReason = "InvalidAccessMode"
+	// ReasonInvalidAnnotation marks other malformed annotation values.
+	ReasonInvalidAnnotation metav1.StatusReason = "InvalidAnnotation"
+	// ReasonForbiddenStorageClass marks a storage class denied by policy.
+	ReasonForbiddenStorageClass metav1.StatusReason = "ForbiddenStorageClass"
+	// ReasonTooManyVolumes marks a pod exceeding the per-pod cap on
+	// converted volumes.
+	ReasonTooManyVolumes metav1.StatusReason = "TooManyVolumes"
+	// ReasonQuotaExceeded marks a conversion denied by a storage budget.
+	ReasonQuotaExceeded metav1.StatusReason = "QuotaExceeded"
+	// ReasonPolicyDenied marks a conversion vetoed by the external policy
+	// engine.
+	ReasonPolicyDenied metav1.StatusReason = "PolicyDenied"
+	// ReasonInternalError marks failures of the webhook itself.
+	ReasonInternalError metav1.StatusReason = "InternalError"
+)
+
+// admissionError is an error with an attached admission reason and code.
+type admissionError struct {
+	reason metav1.StatusReason
+	code   int32
+	msg    string
+}
+
+func (e *admissionError) Error() string { return e.msg }
+
+// denyf builds an admission error with a machine-readable reason and code.
+func denyf(reason metav1.StatusReason, code int32, format string, args ...interface{}) error {
+	return &admissionError{reason: reason, code: code, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ConvertedVolume describes one emptyDir volume the conversion logic rewrote
+// into a PersistentVolumeClaim reference.
+type ConvertedVolume struct {
+	// Volume is the name of the pod volume that was converted.
+	Volume string
+	// Claim is the name of the PersistentVolumeClaim the volume now points at.
+	Claim string
+}
+
+// EvaluatePod runs the conversion decision on a serialized pod and returns the
+// encoded RFC 6902 patch together with the volumes it converts. A nil patch
+// means the pod needs no mutation. This is the library entry point behind
+// pkg/mutator; the admission HTTP handlers do not go through it, so it applies
+// the conversion policy only — no kill switch, canary percentage, or shadow
+// mode.
+func (s *Server) EvaluatePod(raw []byte) ([]byte, []ConvertedVolume, error) {
+	pod := getPod()
+	defer putPod(pod)
+	if err := json.Unmarshal(raw, pod); err != nil {
+		return nil, nil, fmt.Errorf("decoding pod: %v", err)
+	}
+
+	if isMirrorPod(pod) {
+		return nil, nil, nil
+	}
+	if s.cfg.StrictValidation {
+		if err := s.validatePod(pod); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	patch, converted := s.buildPatch(pod, s.namespaceStorageClass(pod.Namespace))
+	defer func() { putPatch(patch) }()
+	if len(patch) == 0 {
+		return nil, nil, nil
+	}
+	encoded, err := json.Marshal(patch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding patch: %v", err)
+	}
+	out := make([]ConvertedVolume, 0, len(converted))
+	for _, cv := range converted {
+		out = append(out, ConvertedVolume{Volume: cv.volume, Claim: cv.claim})
+	}
+	return encoded, out, nil
+}
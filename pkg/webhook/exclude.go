@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"path"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// builtinSidecarVolumes are scratch volumes injected by well-known sidecar
+// webhooks. They are ephemeral by design and converting them breaks the
+// injecting webhook's assumptions, so they are never converted regardless of
+// configuration.
+var builtinSidecarVolumes = []string{
+	"istio-envoy",
+	"istio-data",
+	"linkerd-identity-end-entity",
+	"vault-agent",
+}
+
+// volumeExcluded reports whether the volume must be left as emptyDir because
+// it belongs to an injected sidecar: either its name is on the built-in or
+// configured exclusion list, or it is mounted by a container whose image
+// matches an excluded image pattern.
+func (s *Server) volumeExcluded(pod *admissionPod, name string) bool {
+	for _, excluded := range builtinSidecarVolumes {
+		if name == excluded {
+			return true
+		}
+	}
+	for _, pattern := range s.cfg.ExcludedVolumeNames {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			klog.Warningf("ignoring malformed excluded volume pattern %q: %v", pattern, err)
+			continue
+		}
+		if ok {
+			return true
+		}
+	}
+	if len(s.cfg.ExcludedImages) == 0 {
+		return false
+	}
+	return mountedByExcludedImage(pod.Spec.Containers, name, s.cfg.ExcludedImages) ||
+		mountedByExcludedImage(pod.Spec.InitContainers, name, s.cfg.ExcludedImages)
+}
+
+// mountedByExcludedImage reports whether any container matching an excluded
+// image pattern mounts the volume. Patterns match as substrings so they work
+// across registries and tags, e.g. "istio/proxyv2" matches
+// "docker.io/istio/proxyv2:1.20.0".
+func mountedByExcludedImage(containers []admissionContainer, volume string, patterns []string) bool {
+	for _, container := range containers {
+		if !imageMatches(container.Image, patterns) {
+			continue
+		}
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volume {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func imageMatches(image string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(image, pattern) {
+			return true
+		}
+	}
+	return false
+}
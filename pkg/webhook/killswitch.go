@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// killSwitchKey is the ConfigMap key controlling the kill switch. Setting it
+// to "false" stops all patching immediately without a redeploy.
+const killSwitchKey = "mutations-enabled"
+
+// KillSwitch tracks a ConfigMap key that lets on-call engineers disable
+// mutations at runtime. It fails open: when the ConfigMap or the key is
+// missing, mutations stay enabled.
+type KillSwitch struct {
+	disabled atomic.Bool
+}
+
+// Enabled reports whether mutations are currently allowed.
+func (k *KillSwitch) Enabled() bool {
+	return !k.disabled.Load()
+}
+
+// Watch follows the designated ConfigMap until the context is cancelled,
+// updating the switch as the key changes.
+func (k *KillSwitch) Watch(ctx context.Context, client kubernetes.Interface, namespace, name string) {
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		w, err := client.CoreV1().ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{FieldSelector: selector})
+		if err != nil {
+			klog.Errorf("kill switch: watching configmap %s/%s: %v", namespace, name, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+		for ev := range w.ResultChan() {
+			cm, ok := ev.Object.(*corev1.ConfigMap)
+			if !ok {
+				continue
+			}
+			k.update(cm)
+		}
+		w.Stop()
+	}
+}
+
+func (k *KillSwitch) update(cm *corev1.ConfigMap) {
+	disable := cm.Data[killSwitchKey] == "false"
+	if disable == k.disabled.Load() {
+		return
+	}
+	k.disabled.Store(disable)
+	if disable {
+		klog.Warningf("kill switch: mutations disabled via configmap %s/%s", cm.Namespace, cm.Name)
+	} else {
+		klog.Infof("kill switch: mutations re-enabled via configmap %s/%s", cm.Namespace, cm.Name)
+	}
+}
+
+// SetKillSwitch attaches a runtime kill switch checked before every mutation.
+func (s *Server) SetKillSwitch(k *KillSwitch) {
+	s.killSwitch = k
+}
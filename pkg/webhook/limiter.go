@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+)
+
+// Concurrency limiting: a burst of thousands of pod creations (a big node
+// drain, a namespace-wide rollout) fans out into as many concurrent admission
+// requests, each holding a decoded pod and a goroutine stack. The semaphore
+// caps how many are handled at once; excess requests wait briefly and, if a
+// slot still has not freed up, get a 503 well inside the admission deadline
+// so the API server's failure policy decides, instead of the webhook falling
+// over and taking every admission with it.
+
+// maxAdmissionWait is how long a request waits for a semaphore slot before
+// being rejected. It is kept well under the webhook's typical timeoutSeconds
+// so the rejection, not a timeout, is what the API server sees.
+const maxAdmissionWait = 2 * time.Second
+
+// reviewLimiter is a counting semaphore over admission handling.
+type reviewLimiter struct {
+	slots chan struct{}
+}
+
+// newReviewLimiter returns a limiter allowing n concurrent reviews, or nil
+// when n is zero or negative, meaning unlimited.
+func newReviewLimiter(n int) *reviewLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &reviewLimiter{slots: make(chan struct{}, n)}
+}
+
+// acquire takes a slot, waiting until one frees up or the context ends.
+func (l *reviewLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *reviewLimiter) release() {
+	<-l.slots
+}
+
+// limitConcurrency wraps an admission handler with the server's semaphore.
+// With no limit configured the handler is returned unchanged.
+func (s *Server) limitConcurrency(h http.HandlerFunc) http.HandlerFunc {
+	if s.limiter == nil {
+		return h
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), maxAdmissionWait)
+		defer cancel()
+		if err := s.limiter.acquire(ctx); err != nil {
+			metrics.ReviewsThrottled.Inc()
+			klog.Warningf("rejecting admission request: no review slot freed up within %s", maxAdmissionWait)
+			http.Error(w, "webhook is at its concurrency limit", http.StatusServiceUnavailable)
+			return
+		}
+		defer s.limiter.release()
+		h(w, r)
+	}
+}
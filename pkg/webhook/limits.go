@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/apis/v1alpha1"
+)
+
+// storageLimitRangeGVR locates StorageLimitRange objects via the dynamic
+// client.
+var storageLimitRangeGVR = schema.GroupVersionResource{
+	Group:    v1alpha1.GroupName,
+	Version:  "v1alpha1",
+	Resource: "storagelimitranges",
+}
+
+// limitsCacheTTL bounds how stale a cached StorageLimitRange lookup may be.
+// Admission sits on the pod-creation hot path, so the webhook must not hit
+// the API server for every request.
+const limitsCacheTTL = 30 * time.Second
+
+// sizeLimits are the parsed per-namespace bounds; nil fields are unenforced.
+type sizeLimits struct {
+	min *resource.Quantity
+	max *resource.Quantity
+	def *resource.Quantity
+}
+
+// limitsEntry caches one namespace's limits lookup.
+type limitsEntry struct {
+	limits  *sizeLimits
+	checked time.Time
+}
+
+// SetDynamicClient enables CRD-backed features such as StorageLimitRange
+// clamping. Without it the webhook works normally but namespace size bounds
+// are not enforced.
+func (s *Server) SetDynamicClient(dyn dynamic.Interface) {
+	s.dynamic = dyn
+}
+
+// namespaceLimits returns the namespace's size limits, or nil when no
+// StorageLimitRange exists there, the CRD is not installed, or no dynamic
+// client is configured. Lookups are cached briefly; like the other degraded
+// modes, a failed lookup admits without limits rather than blocking pods.
+func (s *Server) namespaceLimits(namespace string) *sizeLimits {
+	if s.dynamic == nil {
+		return nil
+	}
+	s.limitsMu.Lock()
+	entry, ok := s.limitsCache[namespace]
+	s.limitsMu.Unlock()
+	if ok && time.Since(entry.checked) < limitsCacheTTL {
+		return entry.limits
+	}
+
+	var limits *sizeLimits
+	list, err := s.dynamic.Resource(storageLimitRangeGVR).Namespace(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		klog.V(4).Infof("listing StorageLimitRanges in %q (CRD missing or inaccessible?): %v", namespace, err)
+		return nil
+	}
+	if len(list.Items) > 0 {
+		lr := &v1alpha1.StorageLimitRange{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, lr); err != nil {
+			klog.Warningf("decoding StorageLimitRange in %q: %v", namespace, err)
+			return nil
+		}
+		if len(list.Items) > 1 {
+			klog.Warningf("namespace %q has %d StorageLimitRanges, using %q", namespace, len(list.Items), lr.Name)
+		}
+		limits = &sizeLimits{
+			min: parseQuantityOrWarn(lr.Spec.Min, "StorageLimitRange min"),
+			max: parseQuantityOrWarn(lr.Spec.Max, "StorageLimitRange max"),
+			def: parseQuantityOrWarn(lr.Spec.Default, "StorageLimitRange default"),
+		}
+	}
+
+	s.limitsMu.Lock()
+	if s.limitsCache == nil {
+		s.limitsCache = map[string]limitsEntry{}
+	}
+	s.limitsCache[namespace] = limitsEntry{limits: limits, checked: time.Now()}
+	s.limitsMu.Unlock()
+	return limits
+}
+
+// clampConvertedSizes adjusts each converted volume's size annotation into the
+// namespace's StorageLimitRange: requests outside [min, max] are clamped, and
+// volumes with no size of their own take the range's default. It reports
+// whether any annotation changed, requiring a patch rebuild, plus the
+// warnings to surface to the client.
+func (s *Server) clampConvertedSizes(namespace string, pod *admissionPod, converted []convertedVolume) (bool, []string) {
+	limits := s.namespaceLimits(namespace)
+	if limits == nil {
+		return false, nil
+	}
+	changed := false
+	var warnings []string
+	for _, cv := range converted {
+		raw := pod.Annotations[volumeAnnotation(cv.volume, paramSize)]
+		size, err := parseSizeAnnotation(raw)
+		stamp := false
+		if raw == "" || err != nil {
+			// The volume falls back to a default; prefer the range's own.
+			if limits.def != nil {
+				size = *limits.def
+				stamp = true
+			} else if size, err = parseSizeAnnotation(s.cfg.DefaultSize); err != nil {
+				continue
+			}
+		}
+		if limits.min != nil && size.Cmp(*limits.min) < 0 {
+			warnings = append(warnings, fmt.Sprintf("pvc-webhook: volume %q storage request %s raised to the namespace minimum of %s", cv.volume, size.String(), limits.min.String()))
+			size = *limits.min
+			stamp = true
+		} else if limits.max != nil && size.Cmp(*limits.max) > 0 {
+			warnings = append(warnings, fmt.Sprintf("pvc-webhook: volume %q storage request %s lowered to the namespace maximum of %s", cv.volume, size.String(), limits.max.String()))
+			size = *limits.max
+			stamp = true
+		}
+		if stamp && setPolicyAnnotation(pod, cv.volume, paramSize, size.String()) {
+			changed = true
+		}
+	}
+	return changed, warnings
+}
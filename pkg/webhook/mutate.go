@@ -0,0 +1,519 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/metrics"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/version"
+)
+
+// patchOp is a single JSON Patch (RFC 6902) operation.
+type patchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// maxClaimNameLength is the DNS-1123 subdomain limit enforced on claim names.
+const maxClaimNameLength = 63
+
+// mutate dispatches an admission request to the handler for its object kind
+// and logs a one-line summary per request. The request UID is carried as a
+// structured field on every log line, so all lines for one admission can be
+// correlated with grep.
+func (s *Server) mutate(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	start := time.Now()
+	resp := s.dispatch(req)
+	ns := metrics.NamespaceLabel(req.Namespace)
+	switch {
+	case !resp.Allowed:
+		metrics.ConversionFailures.WithLabelValues(ns).Inc()
+	case len(resp.Patch) > 0:
+		metrics.Conversions.WithLabelValues(ns).Inc()
+	default:
+		metrics.ConversionSkips.WithLabelValues(ns).Inc()
+	}
+	klog.InfoS("admission handled",
+		"uid", req.UID, "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name,
+		"operation", req.Operation, "allowed", resp.Allowed, "patched", len(resp.Patch) > 0,
+		"duration", time.Since(start))
+	return resp
+}
+
+// dispatch routes an admission request to the handler for its object kind.
+func (s *Server) dispatch(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if s.killSwitch != nil && !s.killSwitch.Enabled() {
+		klog.V(1).InfoS("kill switch engaged, admitting unmodified",
+			"uid", req.UID, "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name)
+		return allowResponse()
+	}
+	if s.namespaceExcluded(req.Namespace) {
+		klog.V(2).InfoS("skipping excluded namespace",
+			"uid", req.UID, "kind", req.Kind.Kind, "namespace", req.Namespace)
+		return allowResponse()
+	}
+	if s.namespacePaused(req.Namespace) {
+		klog.V(1).InfoS("namespace is paused, admitting unmodified",
+			"uid", req.UID, "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name)
+		return allowResponse()
+	}
+	if s.namespaceTerminating(req.Namespace) {
+		klog.V(2).InfoS("namespace is terminating, admitting unmodified",
+			"uid", req.UID, "kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name)
+		return allowResponse()
+	}
+	if prefix, ok := templatePathPrefix(req.Kind); ok {
+		return s.mutateWorkload(req, prefix)
+	}
+	return s.mutatePod(req)
+}
+
+// mutatePod rewrites every emptyDir volume in the pod into a reference to a
+// PersistentVolumeClaim. The claims themselves are created asynchronously by
+// the controller, which reads the annotations stamped here.
+func (s *Server) mutatePod(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	pod := getPod()
+	defer putPod(pod)
+	if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+		return errorResponse(denyf(metav1.StatusReasonBadRequest, 400, "decoding pod: %v", err))
+	}
+
+	if isMirrorPod(pod) {
+		// Mirror pods are the API-server reflections of kubelet-managed static
+		// pods; patching them breaks control-plane components and the
+		// controller could never manage their claims anyway.
+		klog.V(2).InfoS("skipping mirror pod",
+			"uid", req.UID, "namespace", req.Namespace, "name", podName(pod))
+		return allowResponse()
+	}
+
+	if s.cfg.StrictValidation {
+		if err := s.validatePod(pod); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	canaryKey := string(pod.UID)
+	if canaryKey == "" {
+		canaryKey = req.Namespace + "/" + podName(pod)
+	}
+	if !s.canarySelected(req.Namespace, canaryKey) {
+		return allowResponse()
+	}
+
+	patch, converted := s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+	defer func() { putPatch(patch) }()
+	if len(patch) == 0 {
+		return allowResponse()
+	}
+	if s.cfg.ShadowMode {
+		s.logShadowDecision(req.UID, "pod", req.Namespace, podName(pod), converted)
+		return allowResponse()
+	}
+	var capWarnings []string
+	if max := s.cfg.MaxVolumesPerPod; max > 0 && len(converted) > max {
+		if s.cfg.RejectExcessVolumes {
+			return errorResponse(denyf(ReasonTooManyVolumes, 403, "pod requests %d converted volumes, the limit is %d", len(converted), max))
+		}
+		capWarnings = capConvertedVolumes(pod, converted, max)
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	if s.applyCELRules(pod, req.Object.Raw, converted) {
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	if adjusted, err := s.applyPolicy(req.Namespace, req.Object.Raw, pod, converted); err != nil {
+		return errorResponse(err)
+	} else if adjusted {
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	clamped, limitWarnings := s.clampConvertedSizes(req.Namespace, pod, converted)
+	if clamped {
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	if err := s.enforceEncryption(req.Namespace, pod, converted); err != nil {
+		return errorResponse(err)
+	}
+	if s.cfg.SchedulingGate && req.Operation == admissionv1.Create {
+		patch = append(patch, schedulingGatePatch(pod)...)
+	}
+	traceAnnotations(patch, req.UID)
+	klog.InfoS("mutating pod",
+		"uid", req.UID, "namespace", req.Namespace, "name", podName(pod), "operations", len(patch))
+	if s.cfg.SyncCreate && s.client != nil && len(converted) > 0 && !isDryRun(req) {
+		s.createClaims(req.Namespace, pod, converted)
+	}
+	s.recordConversions(pod, req.Namespace, converted)
+	if s.cfg.ConversionRecords && s.dynamic != nil && len(converted) > 0 && !isDryRun(req) {
+		s.writeConversionRecords(req, pod, converted)
+	}
+	warnings := append(s.conversionWarnings(pod, converted), capWarnings...)
+	return jsonPatchResponse(patch, append(warnings, limitWarnings...))
+}
+
+// isDryRun reports whether the admission request must not cause side effects.
+func isDryRun(req *admissionv1.AdmissionRequest) bool {
+	return req.DryRun != nil && *req.DryRun
+}
+
+// isMirrorPod reports whether the pod is a kubelet-created mirror of a static
+// pod, marked by the kubernetes.io/config.mirror annotation.
+func isMirrorPod(pod *admissionPod) bool {
+	_, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]
+	return ok
+}
+
+// shadowSkipped counts conversions suppressed by shadow mode since startup.
+var shadowSkipped atomic.Int64
+
+// logShadowDecision records what would have been converted without mutating
+// anything, so shadow-mode rollouts can be evaluated from the logs.
+func (s *Server) logShadowDecision(uid types.UID, kind, namespace, name string, converted []convertedVolume) {
+	for _, cv := range converted {
+		klog.InfoS("shadow mode: would convert volume",
+			"uid", uid, "kind", kind, "namespace", namespace, "name", name,
+			"volume", cv.volume, "claim", cv.claim, "totalSuppressed", shadowSkipped.Add(1))
+	}
+}
+
+// conversionWarnings builds the admission warnings surfaced to the client
+// (e.g. kubectl) so users see that and how their pod was mutated.
+func (s *Server) conversionWarnings(pod *admissionPod, converted []convertedVolume) []string {
+	var warnings []string
+	for _, cv := range converted {
+		warnings = append(warnings, fmt.Sprintf("pvc-webhook: emptyDir volume %q was converted to PersistentVolumeClaim %q", cv.volume, cv.claim))
+		if size := pod.Annotations[volumeAnnotation(cv.volume, paramSize)]; size != "" {
+			if _, err := parseSizeAnnotation(size); err != nil {
+				warnings = append(warnings, fmt.Sprintf("pvc-webhook: invalid size %q for volume %q, the default of %s will be used", size, cv.volume, s.cfg.DefaultSize))
+			}
+		}
+	}
+	return warnings
+}
+
+// recordConversions posts a VolumeConverted event per rewritten volume so the
+// mutation is discoverable via kubectl describe pod.
+func (s *Server) recordConversions(pod *admissionPod, namespace string, converted []convertedVolume) {
+	if s.recorder == nil {
+		return
+	}
+	ref := &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID},
+	}
+	if ref.Namespace == "" {
+		ref.Namespace = namespace
+	}
+	if ref.Name == "" {
+		ref.Name = podName(pod)
+	}
+	for _, cv := range converted {
+		s.recorder.Eventf(ref, corev1.EventTypeNormal, "VolumeConverted",
+			"converted emptyDir volume %q to PersistentVolumeClaim %q", cv.volume, cv.claim)
+	}
+}
+
+// jsonPatchResponse wraps patch operations in an allowed admission response.
+// The patch is encoded through a pooled buffer so only the exact-size result
+// slice is allocated per request.
+func jsonPatchResponse(patch []patchOp, warnings []string) *admissionv1.AdmissionResponse {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := json.NewEncoder(buf).Encode(patch); err != nil {
+		return errorResponse(fmt.Errorf("encoding patch: %v", err))
+	}
+	raw := make([]byte, 0, buf.Len())
+	raw = append(raw, bytes.TrimSuffix(buf.Bytes(), []byte("\n"))...)
+	pt := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		Allowed:   true,
+		Patch:     raw,
+		PatchType: &pt,
+		Warnings:  warnings,
+	}
+}
+
+// buildPatch returns the JSON Patch operations converting the pod's emptyDir
+// volumes, or nil when there is nothing to convert. Volumes already marked
+// converted are skipped individually, so a reinvocation after another webhook
+// appends a fresh emptyDir converts only the new volume. The same applies on
+// UPDATE operations, where previously converted volumes arrive as claim
+// references and must not be patched again. nsClass is the storage class
+// implied by the pod's namespace labels; it is stamped as the storage-class
+// annotation on volumes that do not choose their own.
+func (s *Server) buildPatch(pod *admissionPod, nsClass string) ([]patchOp, []convertedVolume) {
+	patch := getPatch()
+	var converted []convertedVolume
+	added := map[string]string{}
+
+	for i, vol := range pod.Spec.Volumes {
+		if vol.EmptyDir == nil {
+			continue
+		}
+		if pod.Annotations[volumeAnnotation(vol.Name, paramConverted)] == "true" {
+			continue
+		}
+		if !s.volumeNameSelected(vol.Name) {
+			continue
+		}
+		if !s.volumeMeetsMinimum(pod, vol) {
+			continue
+		}
+		if s.volumeExcluded(pod, vol.Name) {
+			continue
+		}
+		claim := claimName(pod, vol.Name)
+		// Guard the replace with a test op: if an earlier mutating webhook
+		// reordered the volumes, the test fails and the API server rejects the
+		// patch instead of silently rewriting the wrong volume.
+		patch = append(patch, patchOp{
+			Op:    "test",
+			Path:  fmt.Sprintf("/spec/volumes/%d/name", i),
+			Value: vol.Name,
+		})
+		patch = append(patch, patchOp{
+			Op:   "replace",
+			Path: fmt.Sprintf("/spec/volumes/%d", i),
+			Value: corev1.Volume{
+				Name: vol.Name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: claim,
+					},
+				},
+			},
+		})
+		added[volumeAnnotation(vol.Name, paramClaim)] = claim
+		added[volumeAnnotation(vol.Name, paramConverted)] = "true"
+		// Friendly size spellings are normalized into the stamped annotation
+		// so the controller and kubectl see the canonical quantity.
+		if raw := pod.Annotations[volumeAnnotation(vol.Name, paramSize)]; raw != "" {
+			if q, err := parseSizeAnnotation(raw); err == nil && q.String() != raw {
+				added[volumeAnnotation(vol.Name, paramSize)] = q.String()
+			}
+		}
+		if nsClass != "" && pod.Annotations[volumeAnnotation(vol.Name, paramStorageClass)] == "" {
+			added[volumeAnnotation(vol.Name, paramStorageClass)] = nsClass
+		}
+		converted = append(converted, convertedVolume{volume: vol.Name, claim: claim})
+	}
+	if len(converted) > 0 {
+		added[annotations.WebhookVersion] = version.Short()
+	}
+	patch = append(patch, annotationsPatch(pod, added)...)
+	return patch, converted
+}
+
+// volumeMeetsMinimum reports whether the volume's declared size reaches the
+// configured minimum. The size annotation wins over the emptyDir sizeLimit;
+// a volume declaring neither stays emptyDir when a minimum is set, so small
+// scratch dirs do not each get a claim.
+func (s *Server) volumeMeetsMinimum(pod *admissionPod, vol admissionVolume) bool {
+	if s.minSize == nil {
+		return true
+	}
+	if v := pod.Annotations[volumeAnnotation(vol.Name, paramSize)]; v != "" {
+		if size, err := parseSizeAnnotation(v); err == nil {
+			return size.Cmp(*s.minSize) >= 0
+		}
+	}
+	if vol.EmptyDir.SizeLimit != nil {
+		return vol.EmptyDir.SizeLimit.Cmp(*s.minSize) >= 0
+	}
+	return false
+}
+
+// SchedulingGateName is the scheduling gate inserted on converted pods and
+// lifted by the controller once their claims are bound.
+const SchedulingGateName = "pvc-webhook.io/claims-bound"
+
+// schedulingGatePatch gates the pod's scheduling until the controller
+// confirms the converted claims are ready.
+func schedulingGatePatch(pod *admissionPod) []patchOp {
+	for _, gate := range pod.Spec.SchedulingGates {
+		if gate.Name == SchedulingGateName {
+			return nil
+		}
+	}
+	gate := corev1.PodSchedulingGate{Name: SchedulingGateName}
+	if len(pod.Spec.SchedulingGates) == 0 {
+		return []patchOp{{
+			Op:    "add",
+			Path:  "/spec/schedulingGates",
+			Value: []corev1.PodSchedulingGate{gate},
+		}}
+	}
+	return []patchOp{{
+		Op:    "add",
+		Path:  "/spec/schedulingGates/-",
+		Value: gate,
+	}}
+}
+
+// convertedVolume describes one volume rewritten by buildPatch.
+type convertedVolume struct {
+	volume string
+	claim  string
+}
+
+// annotationsPatch returns a single operation writing all added annotations.
+// JSON Patch "add" replaces an existing member, so one op covers both pods
+// with and without an annotations map, and the patch stays small and immune to
+// earlier webhooks replacing the map mid-sequence.
+func annotationsPatch(pod *admissionPod, added map[string]string) []patchOp {
+	if len(added) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(pod.Annotations)+len(added))
+	for k, v := range pod.Annotations {
+		merged[k] = v
+	}
+	for k, v := range added {
+		merged[k] = v
+	}
+	return []patchOp{{
+		Op:    "add",
+		Path:  "/metadata/annotations",
+		Value: merged,
+	}}
+}
+
+// traceAnnotations stamps the mutated-by and admission UID annotations into
+// the patch's annotations operation, so a converted pod can be correlated
+// with the release that rewrote it and the admission request in the webhook's
+// logs. The operation exists whenever anything was converted.
+func traceAnnotations(patch []patchOp, uid types.UID) {
+	for i := range patch {
+		if patch[i].Path != "/metadata/annotations" {
+			continue
+		}
+		if merged, ok := patch[i].Value.(map[string]string); ok {
+			merged[annotations.MutatedBy] = version.Short() + "@" + time.Now().UTC().Format(time.RFC3339)
+			if uid != "" {
+				merged[annotations.AdmissionUID] = string(uid)
+			}
+		}
+		return
+	}
+}
+
+// claimName derives the PVC name for a pod volume, truncated to fit the
+// DNS-1123 length limit. A user-provided identity annotation takes priority
+// so unrelated pods can deliberately share one claim; pods owned by a Job are
+// named after the Job so that retried pods reuse one scratch volume.
+func claimName(pod *admissionPod, volume string) string {
+	if id := pod.Annotations[volumeAnnotation(volume, paramIdentity)]; id != "" {
+		return truncateName(id)
+	}
+	base := podName(pod)
+	if pod.Annotations[volumeAnnotation(volume, paramShared)] == "true" {
+		if wl := workloadBase(pod); wl != "" {
+			base = wl
+		}
+	} else if job := owningJob(pod); job != "" {
+		base = job
+	}
+	return truncateName(fmt.Sprintf("%s-%s", base, volume))
+}
+
+// truncateName fits a claim name into the DNS-1123 length limit. Names that
+// must be shortened get a short stable hash of the full name appended, so two
+// long names differing only past the cut-off cannot silently share a claim.
+func truncateName(name string) string {
+	if len(name) <= maxClaimNameLength {
+		return strings.Trim(name, "-")
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	name = name[:maxClaimNameLength-len(suffix)-1]
+	return strings.Trim(name, "-") + "-" + suffix
+}
+
+// workloadBase returns a name stable across all replicas of the pod's owning
+// workload. ReplicaSet names carry a per-revision template hash, which is
+// stripped so the claim survives Deployment rollouts.
+func workloadBase(pod *admissionPod) string {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+		name := ref.Name
+		if ref.Kind == "ReplicaSet" {
+			if i := strings.LastIndex(name, "-"); i > 0 {
+				name = name[:i]
+			}
+		}
+		return name
+	}
+	return ""
+}
+
+// owningJob returns the name of the Job controlling the pod, or "".
+func owningJob(pod *admissionPod) string {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller && ref.Kind == "Job" && strings.HasPrefix(ref.APIVersion, "batch/") {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// podName returns the pod's name, falling back to generateName for pods
+// admitted before the API server assigns the final name.
+func podName(pod *admissionPod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return strings.TrimSuffix(pod.GenerateName, "-")
+}
+
+func allowResponse() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+// errorResponse denies admission, carrying the error's reason and code when
+// it is an admissionError and reporting an internal error otherwise.
+func errorResponse(err error) *admissionv1.AdmissionResponse {
+	reason, code := ReasonInternalError, int32(500)
+	if ae, ok := err.(*admissionError); ok {
+		reason, code = ae.reason, ae.code
+	}
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  reason,
+			Code:    code,
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// serveMutatePVC applies claim policy to user-created PVCs, so the same
+// defaults and bounds govern both converted volumes and claims created by
+// hand.
+func (s *Server) serveMutatePVC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := getBuffer()
+	defer putBuffer(body)
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, fmt.Sprintf("reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	out, err := s.reviewWith(body.Bytes(), s.mutatePVC)
+	if err != nil {
+		klog.Errorf("pvc admission review failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(out); err != nil {
+		klog.Errorf("writing admission response: %v", err)
+	}
+}
+
+// mutatePVC injects the default storage class, the standard claim labels, and
+// clamps the storage request to the configured floor and ceiling.
+func (s *Server) mutatePVC(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if s.killSwitch != nil && !s.killSwitch.Enabled() {
+		return allowResponse()
+	}
+	if s.namespaceExcluded(req.Namespace) {
+		return allowResponse()
+	}
+	var pvc corev1.PersistentVolumeClaim
+	if err := json.Unmarshal(req.Object.Raw, &pvc); err != nil {
+		return errorResponse(denyf(metav1.StatusReasonBadRequest, 400, "decoding claim: %v", err))
+	}
+
+	patch := getPatch()
+	defer func() { putPatch(patch) }()
+	var warnings []string
+
+	// The namespace's tier label wins over the global default, so bare claims
+	// follow the same storage selection as converted volumes.
+	class := s.namespaceStorageClass(req.Namespace)
+	if class == "" {
+		class = s.cfg.DefaultStorageClass
+	}
+	if pvc.Spec.StorageClassName != nil && *pvc.Spec.StorageClassName != "" {
+		class = *pvc.Spec.StorageClassName
+	} else if class != "" {
+		patch = append(patch, patchOp{
+			Op:    "add",
+			Path:  "/spec/storageClassName",
+			Value: class,
+		})
+	}
+	// Bare claims in sensitive namespaces are held to the same encryption
+	// policy as converted volumes.
+	if s.namespaceSensitive(req.Namespace) {
+		if class == "" {
+			return errorResponse(denyf(ReasonForbiddenStorageClass, 403,
+				"namespace %q handles sensitive data; the claim must name an encrypted storage class explicitly", req.Namespace))
+		}
+		if !s.classEncrypted(class) {
+			return errorResponse(denyf(ReasonForbiddenStorageClass, 403,
+				"namespace %q handles sensitive data; storage class %q is not marked as encrypted", req.Namespace, class))
+		}
+	}
+
+	if len(s.cfg.ClaimLabels) > 0 {
+		merged := make(map[string]string, len(pvc.Labels)+len(s.cfg.ClaimLabels))
+		changed := false
+		for k, v := range pvc.Labels {
+			merged[k] = v
+		}
+		for k, v := range s.cfg.ClaimLabels {
+			if merged[k] != v {
+				merged[k] = v
+				changed = true
+			}
+		}
+		if changed {
+			patch = append(patch, patchOp{Op: "add", Path: "/metadata/labels", Value: merged})
+		}
+	}
+
+	if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		// A namespace's StorageLimitRange overrides the cluster-wide bounds.
+		floor, ceiling := s.sizeFloor, s.sizeCeiling
+		floorOrigin, ceilingOrigin := "cluster floor", "cluster ceiling"
+		if limits := s.namespaceLimits(req.Namespace); limits != nil {
+			if limits.min != nil {
+				floor, floorOrigin = limits.min, "namespace minimum"
+			}
+			if limits.max != nil {
+				ceiling, ceilingOrigin = limits.max, "namespace maximum"
+			}
+		}
+		if floor != nil && request.Cmp(*floor) < 0 {
+			patch = append(patch, patchOp{Op: "replace", Path: "/spec/resources/requests/storage", Value: floor.String()})
+			warnings = append(warnings, fmt.Sprintf("pvc-webhook: storage request %s raised to the %s of %s", request.String(), floorOrigin, floor.String()))
+		} else if ceiling != nil && request.Cmp(*ceiling) > 0 {
+			patch = append(patch, patchOp{Op: "replace", Path: "/spec/resources/requests/storage", Value: ceiling.String()})
+			warnings = append(warnings, fmt.Sprintf("pvc-webhook: storage request %s lowered to the %s of %s", request.String(), ceilingOrigin, ceiling.String()))
+		}
+	}
+
+	if len(patch) == 0 {
+		return allowResponse()
+	}
+	klog.InfoS("mutating claim",
+		"uid", req.UID, "namespace", req.Namespace, "name", pvc.Name, "operations", len(patch))
+	return jsonPatchResponse(patch, warnings)
+}
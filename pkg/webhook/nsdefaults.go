@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+)
+
+// Namespace-label-driven defaults let platform teams steer storage selection
+// from their existing namespace classification scheme: a namespace labeled
+// storage.company.io/tier=fast gets its converted volumes on the class that
+// tier maps to, without per-pod annotations.
+
+// StartNamespaceInformer begins caching namespaces so admission requests can
+// consult namespace labels without a per-request API call. It blocks until the
+// cache is synced and must be called before serving when NamespaceTierLabel is
+// set.
+func (s *Server) StartNamespaceInformer(ctx context.Context, client kubernetes.Interface) {
+	factory := informers.NewSharedInformerFactory(client, 10*time.Minute)
+	s.nsLister = factory.Core().V1().Namespaces().Lister()
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	klog.Info("namespace informer synced")
+}
+
+// namespaceStorageClass returns the storage class implied by the namespace's
+// tier label, or "" when the feature is off, the namespace is unlabeled, or
+// the tier is unknown. With no tier-to-class mapping configured the label
+// value is used as the class name directly.
+func (s *Server) namespaceStorageClass(namespace string) string {
+	if s.cfg.NamespaceTierLabel == "" || s.nsLister == nil {
+		return ""
+	}
+	ns, err := s.nsLister.Get(namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.V(2).Infof("looking up namespace %q: %v", namespace, err)
+		}
+		return ""
+	}
+	tier := ns.Labels[s.cfg.NamespaceTierLabel]
+	if tier == "" {
+		return ""
+	}
+	if len(s.cfg.NamespaceTierClasses) == 0 {
+		return tier
+	}
+	class, ok := s.cfg.NamespaceTierClasses[tier]
+	if !ok {
+		klog.Warningf("namespace %q has unmapped tier %q, using global defaults", namespace, tier)
+	}
+	return class
+}
+
+// namespacePaused reports whether the namespace carries the pause annotation,
+// which suspends conversions during migrations or incidents. Without the
+// namespace informer the answer is always false.
+func (s *Server) namespacePaused(namespace string) bool {
+	if s.nsLister == nil {
+		return false
+	}
+	ns, err := s.nsLister.Get(namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.V(2).Infof("looking up namespace %q: %v", namespace, err)
+		}
+		return false
+	}
+	return ns.Annotations[annotations.NamespacePaused] == "true"
+}
+
+// namespaceTerminating reports whether the namespace is being deleted.
+// Conversion there is pointless — the controller could never create the
+// claims — so such pods are admitted unmodified. Without the namespace
+// informer the answer is always false.
+func (s *Server) namespaceTerminating(namespace string) bool {
+	if s.nsLister == nil {
+		return false
+	}
+	ns, err := s.nsLister.Get(namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.V(2).Infof("looking up namespace %q: %v", namespace, err)
+		}
+		return false
+	}
+	return ns.DeletionTimestamp != nil
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// External policy hook: organizations with a central policy engine (OPA, a
+// custom service) can govern storage decisions without forking the webhook.
+// Before committing a conversion, the webhook POSTs the admitted object and
+// the proposed conversions to the configured URL; the engine can veto the
+// admission outright or adjust the parameters per volume. OPA serves this
+// shape directly from a rule that wraps its input/result envelope.
+
+// policyConversion describes one proposed conversion, with the effective
+// parameters after annotation and default resolution. In a decision it
+// carries the engine's adjustments; a volume omitted from the decision stays
+// emptyDir.
+type policyConversion struct {
+	Volume       string   `json:"volume"`
+	Claim        string   `json:"claim"`
+	Size         string   `json:"size,omitempty"`
+	StorageClass string   `json:"storageClass,omitempty"`
+	AccessModes  []string `json:"accessModes,omitempty"`
+}
+
+// policyRequest is the body POSTed to the policy engine.
+type policyRequest struct {
+	// Namespace is the namespace being admitted into.
+	Namespace string `json:"namespace"`
+	// Object is the admitted manifest as received: a Pod, or the workload
+	// object when a pod template is being mutated.
+	Object json.RawMessage `json:"object"`
+	// Conversions are the conversions the webhook proposes.
+	Conversions []policyConversion `json:"conversions"`
+}
+
+// policyDecision is the engine's answer.
+type policyDecision struct {
+	// Allowed false vetoes the admission entirely.
+	Allowed bool `json:"allowed"`
+	// Reason is surfaced to the client on a veto.
+	Reason string `json:"reason,omitempty"`
+	// Conversions, when present, replaces the proposed set: volumes omitted
+	// stay emptyDir and changed parameters are applied. Absent means the
+	// proposal stands unchanged.
+	Conversions []policyConversion `json:"conversions"`
+}
+
+// applyPolicy consults the configured policy engine about the proposed
+// conversions. It returns an error to deny admission, and true when the
+// engine adjusted the proposal and the patch must be rebuilt. With no engine
+// configured it is a no-op.
+func (s *Server) applyPolicy(namespace string, raw []byte, pod *admissionPod, converted []convertedVolume) (bool, error) {
+	if s.policyClient == nil || len(converted) == 0 {
+		return false, nil
+	}
+	decision, err := s.policyCallout(namespace, raw, pod, converted)
+	if err != nil {
+		if s.cfg.PolicyFailClosed {
+			return false, denyf(ReasonInternalError, 500, "policy engine unavailable: %v", err)
+		}
+		klog.Warningf("policy engine unavailable, converting without policy: %v", err)
+		return false, nil
+	}
+	if !decision.Allowed {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "conversion denied by policy"
+		}
+		return false, denyf(ReasonPolicyDenied, 403, "%s", reason)
+	}
+	if decision.Conversions == nil {
+		return false, nil
+	}
+	return s.applyPolicyAdjustments(pod, converted, decision.Conversions), nil
+}
+
+// policyCallout posts the proposal and decodes the decision.
+func (s *Server) policyCallout(namespace string, raw []byte, pod *admissionPod, converted []convertedVolume) (*policyDecision, error) {
+	req := policyRequest{Namespace: namespace, Object: raw}
+	for _, cv := range converted {
+		size := pod.Annotations[volumeAnnotation(cv.volume, paramSize)]
+		if size == "" {
+			size = s.cfg.DefaultSize
+		}
+		class := pod.Annotations[volumeAnnotation(cv.volume, paramStorageClass)]
+		if class == "" {
+			class = s.cfg.DefaultStorageClass
+		}
+		modes := s.cfg.DefaultAccessModes
+		if v := pod.Annotations[volumeAnnotation(cv.volume, paramAccessModes)]; v != "" {
+			modes = strings.Split(v, ",")
+		}
+		req.Conversions = append(req.Conversions, policyConversion{
+			Volume:       cv.volume,
+			Claim:        cv.claim,
+			Size:         size,
+			StorageClass: class,
+			AccessModes:  modes,
+		})
+	}
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encoding policy request: %v", err)
+	}
+	resp, err := s.policyClient.Post(s.cfg.PolicyURL, "application/json", bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy engine returned status %d", resp.StatusCode)
+	}
+	decision := &policyDecision{}
+	if err := json.NewDecoder(resp.Body).Decode(decision); err != nil {
+		return nil, fmt.Errorf("decoding policy decision: %v", err)
+	}
+	return decision, nil
+}
+
+// applyPolicyAdjustments rewrites the in-memory pod to match the engine's
+// decision: vetoed volumes stop looking like emptyDirs so the rebuilt patch
+// skips them, and parameter overrides land in the annotations the patch
+// builder stamps. It reports whether anything changed.
+func (s *Server) applyPolicyAdjustments(pod *admissionPod, converted []convertedVolume, want []policyConversion) bool {
+	byVolume := make(map[string]policyConversion, len(want))
+	for _, c := range want {
+		byVolume[c.Volume] = c
+	}
+	changed := false
+	for _, cv := range converted {
+		adj, ok := byVolume[cv.volume]
+		if !ok {
+			for i := range pod.Spec.Volumes {
+				if pod.Spec.Volumes[i].Name == cv.volume {
+					pod.Spec.Volumes[i].EmptyDir = nil
+				}
+			}
+			klog.Infof("policy vetoed conversion of volume %q in pod %s", cv.volume, podName(pod))
+			changed = true
+			continue
+		}
+		changed = setPolicyAnnotation(pod, cv.volume, paramSize, adj.Size) || changed
+		changed = setPolicyAnnotation(pod, cv.volume, paramStorageClass, adj.StorageClass) || changed
+		changed = setPolicyAnnotation(pod, cv.volume, paramAccessModes, strings.Join(adj.AccessModes, ",")) || changed
+	}
+	return changed
+}
+
+// setPolicyAnnotation writes a policy override into the pod's annotations,
+// where the rebuilt patch persists it for the controller. Empty values leave
+// the proposal alone.
+func setPolicyAnnotation(pod *admissionPod, volume, param, value string) bool {
+	if value == "" {
+		return false
+	}
+	key := volumeAnnotation(volume, param)
+	if pod.Annotations[key] == value {
+		return false
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[key] = value
+	return true
+}
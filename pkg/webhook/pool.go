@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"bytes"
+	"sync"
+)
+
+// The webhook sits on the pod-creation critical path, so the hot path reuses
+// its larger allocations across requests: request body buffers, decoded pod
+// structs and patch slices all come from pools.
+
+// bufferPool holds scratch byte buffers for reading request bodies and
+// encoding responses.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// podPool holds decode targets for incoming pods and pod templates.
+var podPool = sync.Pool{
+	New: func() interface{} { return new(admissionPod) },
+}
+
+func getPod() *admissionPod {
+	return podPool.Get().(*admissionPod)
+}
+
+func putPod(pod *admissionPod) {
+	*pod = admissionPod{}
+	podPool.Put(pod)
+}
+
+// patchPool holds patch operation slices; they are handed back once the
+// response is encoded.
+var patchPool = sync.Pool{
+	New: func() interface{} { return make([]patchOp, 0, 8) },
+}
+
+func getPatch() []patchOp {
+	return patchPool.Get().([]patchOp)[:0]
+}
+
+func putPatch(patch []patchOp) {
+	patchPool.Put(patch[:0]) //nolint:staticcheck // slice reuse is the point
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// The /preview endpoint answers "what will the webhook do to this pod"
+// outside of admission: it takes a bare Pod manifest (not an AdmissionReview)
+// and returns the fully mutated pod together with the claims that would be
+// created, so platform teams can check manifests in CI before they ever reach
+// a cluster. It runs the conversion policy only, like EvaluatePod — no kill
+// switch, canary percentage, or shadow mode — because CI wants the steady-state
+// answer, not the state of a rollout.
+
+// previewResponse is the JSON body returned by /preview.
+type previewResponse struct {
+	// Pod is the manifest as the webhook would admit it.
+	Pod json.RawMessage `json:"pod"`
+	// Patch is the RFC 6902 patch that was applied; null when the pod needs
+	// no mutation.
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// Claims are the PersistentVolumeClaims the conversion would create.
+	Claims []*corev1.PersistentVolumeClaim `json:"claims,omitempty"`
+}
+
+// servePreview handles POSTs of pod manifests. The endpoint is disabled until
+// a bearer token is configured, so it cannot become an anonymous oracle for
+// cluster configuration.
+func (s *Server) servePreview(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.PreviewAuthToken == "" {
+		http.Error(w, "preview is disabled; configure a preview auth token", http.StatusForbidden)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.PreviewAuthToken)) != 1 {
+		http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	body := getBuffer()
+	defer putBuffer(body)
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, fmt.Sprintf("reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	patch, converted, err := s.EvaluatePod(body.Bytes())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	mutated := json.RawMessage(body.Bytes())
+	if patch != nil {
+		decoded, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("decoding patch: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if mutated, err = decoded.Apply(body.Bytes()); err != nil {
+			http.Error(w, fmt.Sprintf("applying patch: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	resp := previewResponse{Pod: mutated, Patch: patch}
+	if len(converted) > 0 {
+		pod := getPod()
+		defer putPod(pod)
+		if err := json.Unmarshal(body.Bytes(), pod); err == nil {
+			for _, cv := range converted {
+				resp.Claims = append(resp.Claims, s.claimObject(pod.Namespace, pod, cv.Volume, cv.Claim))
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		klog.Errorf("writing preview response: %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+// Package webhook implements the mutating admission webhook that rewrites
+// emptyDir volumes in pod specs into PersistentVolumeClaim references. The
+// companion controller (pkg/controller) is responsible for creating the
+// claims the rewritten volumes point at.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/version"
+)
+
+// Config carries the defaults applied to converted volumes when the pod
+// does not specify its own parameters via annotations.
+type Config struct {
+	// DefaultSize is the storage request used when a volume has no size
+	// annotation, e.g. "2Gi".
+	DefaultSize string
+	// DefaultStorageClass is the storage class for created claims. Empty
+	// means the cluster default.
+	DefaultStorageClass string
+	// DefaultAccessModes are the access modes requested for created claims.
+	DefaultAccessModes []string
+	// ExcludedNamespaces lists namespaces whose pods are never mutated, in
+	// addition to the built-in system namespaces.
+	ExcludedNamespaces []string
+	// ClaimLabels are stamped on every PVC admitted via the bare-claim
+	// mutation endpoint.
+	ClaimLabels map[string]string
+	// SizeFloor and SizeCeiling clamp the storage request of bare claims,
+	// e.g. "1Gi" and "1Ti". Empty disables the respective bound.
+	SizeFloor   string
+	SizeCeiling string
+	// MinimumSize leaves volumes below this size as emptyDir, e.g. "1Gi".
+	// The size of a volume is its size annotation or, failing that, the
+	// emptyDir sizeLimit; volumes declaring neither are not converted when a
+	// minimum is set. Empty converts regardless of size.
+	MinimumSize string
+	// VolumeNamePatterns restricts conversion to volumes whose name matches
+	// one of these glob patterns (e.g. "data-*", "cache"). Empty converts
+	// every emptyDir. Sidecar scratch volumes such as istio-envoy typically
+	// should not match any pattern.
+	VolumeNamePatterns []string
+	// ExcludedVolumeNames are glob patterns for volume names that are never
+	// converted, on top of the built-in sidecar volume list.
+	ExcludedVolumeNames []string
+	// ExcludedImages are substrings of container images whose mounted
+	// volumes are never converted, e.g. "istio/proxyv2" or "linkerd-proxy".
+	ExcludedImages []string
+	// StrictValidation denies admission when per-volume annotations carry
+	// unparsable values, instead of letting the controller fall back to
+	// defaults later.
+	StrictValidation bool
+	// ShadowMode evaluates pods and logs what would have been patched but
+	// always returns an unmodified allow response. Used to assess impact
+	// before enabling mutation in a production cluster.
+	ShadowMode bool
+	// ConversionPercentage converts only this deterministic fraction of
+	// eligible objects (hashed by UID), enabling gradual rollout. 100
+	// converts everything.
+	ConversionPercentage int
+	// NamespacePercentages overrides ConversionPercentage per namespace.
+	NamespacePercentages map[string]int
+	// SyncCreate makes the webhook create each converted volume's claim
+	// before returning the patch, instead of leaving creation to the
+	// controller. Requires a client via SetClient; admission dry-run
+	// requests never create anything.
+	SyncCreate bool
+	// NamespaceTierLabel is the namespace label consulted for storage
+	// selection, e.g. "storage.company.io/tier". Empty disables namespace
+	// defaults. Requires StartNamespaceInformer.
+	NamespaceTierLabel string
+	// NamespaceTierClasses maps tier label values onto storage classes, e.g.
+	// {"fast": "premium-ssd"}. Empty uses the label value as the class name.
+	NamespaceTierClasses map[string]string
+	// SensitiveNamespaceLabel marks namespaces handling sensitive data, as
+	// "key" or "key=value". Pods there may only use encrypted storage
+	// classes. Requires StartNamespaceInformer.
+	SensitiveNamespaceLabel string
+	// EncryptedStorageClasses names classes considered encrypted at rest, on
+	// top of those whose parameters say so.
+	EncryptedStorageClasses []string
+	// SchedulingGate inserts a scheduling gate on converted pods that the
+	// controller lifts once their claims are bound, closing the race where
+	// a pod schedules before its claims exist.
+	SchedulingGate bool
+	// MaxConcurrentReviews caps how many admission requests are handled at
+	// once; excess requests wait briefly for a slot and are rejected if none
+	// frees up. Zero means unlimited.
+	MaxConcurrentReviews int
+	// PreviewAuthToken is the bearer token required by the /preview endpoint.
+	// Empty disables the endpoint.
+	PreviewAuthToken string
+	// PolicyURL is the endpoint of an external policy engine consulted before
+	// each conversion; it can veto the admission or adjust parameters per
+	// volume. Empty disables the callout.
+	PolicyURL string
+	// PolicyTimeout bounds the policy callout. Zero means 2s.
+	PolicyTimeout time.Duration
+	// PolicyFailClosed denies admission when the policy engine is
+	// unreachable; the default converts without policy and logs a warning.
+	PolicyFailClosed bool
+	// CELRules compute conversion parameters from pod fields with CEL
+	// expressions; see CELRule. Empty disables CEL evaluation.
+	CELRules []CELRule
+	// MaxVolumesPerPod caps how many volumes of one pod are converted; zero
+	// means unlimited. Volumes beyond the cap are left as emptyDir with a
+	// warning, unless RejectExcessVolumes is set.
+	MaxVolumesPerPod int
+	// RejectExcessVolumes denies admission outright when a pod exceeds
+	// MaxVolumesPerPod, instead of converting only the first volumes.
+	RejectExcessVolumes bool
+	// ConversionRecords persists a ConversionRecord object per converted
+	// volume as an in-cluster audit trail. Requires a dynamic client via
+	// SetDynamicClient; the controller removes records past their TTL.
+	ConversionRecords bool
+}
+
+// builtinExcludedNamespaces are never mutated regardless of configuration or
+// webhook selectors; converting system pods can take down the cluster.
+var builtinExcludedNamespaces = []string{
+	metav1.NamespaceSystem,
+	"kube-node-lease",
+}
+
+// namespaceExcluded reports whether pods in the namespace must be left alone.
+func (s *Server) namespaceExcluded(namespace string) bool {
+	for _, ns := range builtinExcludedNamespaces {
+		if namespace == ns {
+			return true
+		}
+	}
+	for _, ns := range s.cfg.ExcludedNamespaces {
+		if namespace == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeNameSelected reports whether a volume name is eligible for conversion
+// under VolumeNamePatterns. Malformed patterns are skipped so one bad entry
+// cannot disable the rest.
+func (s *Server) volumeNameSelected(name string) bool {
+	if len(s.cfg.VolumeNamePatterns) == 0 {
+		return true
+	}
+	for _, pattern := range s.cfg.VolumeNamePatterns {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			klog.Warningf("ignoring malformed volume name pattern %q: %v", pattern, err)
+			continue
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Server handles admission review requests for pods.
+type Server struct {
+	cfg          Config
+	minSize      *resource.Quantity
+	sizeFloor    *resource.Quantity
+	sizeCeiling  *resource.Quantity
+	client       kubernetes.Interface
+	dynamic      dynamic.Interface
+	nsLister     listersv1.NamespaceLister
+	recorder     record.EventRecorder
+	killSwitch   *KillSwitch
+	limiter      *reviewLimiter
+	policyClient *http.Client
+	celRules     []celProgram
+
+	encryptedMu      sync.Mutex
+	encryptedClasses map[string]bool
+
+	limitsMu    sync.Mutex
+	limitsCache map[string]limitsEntry
+}
+
+// NewServer returns a Server applying the given defaults.
+func NewServer(cfg Config) *Server {
+	s := &Server{cfg: cfg}
+	if cfg.MinimumSize != "" {
+		if min, err := resource.ParseQuantity(cfg.MinimumSize); err != nil {
+			klog.Warningf("ignoring invalid minimum size %q: %v", cfg.MinimumSize, err)
+		} else {
+			s.minSize = &min
+		}
+	}
+	s.sizeFloor = parseQuantityOrWarn(cfg.SizeFloor, "size floor")
+	s.sizeCeiling = parseQuantityOrWarn(cfg.SizeCeiling, "size ceiling")
+	s.limiter = newReviewLimiter(cfg.MaxConcurrentReviews)
+	if cfg.PolicyURL != "" {
+		timeout := cfg.PolicyTimeout
+		if timeout == 0 {
+			timeout = 2 * time.Second
+		}
+		s.policyClient = &http.Client{Timeout: timeout}
+	}
+	s.celRules = compileCELRules(cfg.CELRules)
+	return s
+}
+
+// parseQuantityOrWarn parses an optional quantity config value, returning nil
+// (and logging) when it is empty or malformed.
+func parseQuantityOrWarn(value, what string) *resource.Quantity {
+	if value == "" {
+		return nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		klog.Warningf("ignoring invalid %s %q: %v", what, value, err)
+		return nil
+	}
+	return &q
+}
+
+// SetEventRecorder enables posting Kubernetes Events for conversions. Without
+// a recorder the webhook works normally but mutations are only logged.
+func (s *Server) SetEventRecorder(recorder record.EventRecorder) {
+	s.recorder = recorder
+}
+
+// Handler returns the HTTP handler serving the admission endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mutate", s.limitConcurrency(s.serveMutate))
+	mux.HandleFunc("/convert", s.limitConcurrency(s.serveConvert))
+	mux.HandleFunc("/mutate-pvc", s.limitConcurrency(s.serveMutatePVC))
+	mux.HandleFunc("/validate-pvc-delete", s.limitConcurrency(s.serveValidatePVCDelete))
+	mux.HandleFunc("/preview", s.limitConcurrency(s.servePreview))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(version.Get()); err != nil {
+			klog.Errorf("writing version response: %v", err)
+		}
+	})
+	return mux
+}
+
+func (s *Server) serveMutate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := getBuffer()
+	defer putBuffer(body)
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, fmt.Sprintf("reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	out, err := s.review(body.Bytes())
+	if err != nil {
+		klog.Errorf("admission review failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(out); err != nil {
+		klog.Errorf("writing admission response: %v", err)
+	}
+}
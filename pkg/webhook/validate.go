@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apiannotations "github.com/rkitindi-kr/pvc-webhook/pkg/apis/annotations"
+)
+
+// validatePod checks the per-volume annotations of every emptyDir volume and
+// returns a descriptive error for the first invalid value. It is only invoked
+// in strict mode; otherwise invalid values fall back to defaults downstream.
+func (s *Server) validatePod(pod *admissionPod) error {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.EmptyDir == nil {
+			continue
+		}
+		if size := pod.Annotations[volumeAnnotation(vol.Name, paramSize)]; size != "" {
+			if _, err := parseSizeAnnotation(size); err != nil {
+				return denyf(ReasonInvalidSize, 422, "volume %q: %v", vol.Name, err)
+			}
+		}
+		if modes := pod.Annotations[volumeAnnotation(vol.Name, paramAccessModes)]; modes != "" {
+			for _, m := range strings.Split(modes, ",") {
+				if !apiannotations.ValidAccessMode(strings.TrimSpace(m)) {
+					return denyf(ReasonInvalidAccessMode, 422, "volume %q: unknown access mode %q", vol.Name, strings.TrimSpace(m))
+				}
+			}
+		}
+		if sel := pod.Annotations[volumeAnnotation(vol.Name, paramSelector)]; sel != "" {
+			labels := map[string]string{}
+			if err := json.Unmarshal([]byte(sel), &labels); err != nil {
+				return denyf(ReasonInvalidAnnotation, 422, "volume %q: invalid selector %q: want a JSON object of labels", vol.Name, sel)
+			}
+		}
+		if mode := pod.Annotations[volumeAnnotation(vol.Name, paramVolumeMode)]; mode != "" {
+			switch corev1.PersistentVolumeMode(mode) {
+			case corev1.PersistentVolumeFilesystem, corev1.PersistentVolumeBlock:
+			default:
+				return denyf(ReasonInvalidAnnotation, 422, "volume %q: unknown volumeMode %q, want Filesystem or Block", vol.Name, mode)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// annotationForceDelete overrides the deletion protection on a managed claim,
+// for operators who really do want the data gone.
+const annotationForceDelete = "pvc-webhook/force-delete"
+
+// serveValidatePVCDelete guards managed claims against accidental deletion:
+// a PVC labeled created-by=pvc-webhook cannot be deleted while a live pod
+// still mounts it, unless the force annotation is set.
+func (s *Server) serveValidatePVCDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := getBuffer()
+	defer putBuffer(body)
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, fmt.Sprintf("reading request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	out, err := s.reviewWith(body.Bytes(), s.validatePVCDelete)
+	if err != nil {
+		klog.Errorf("pvc delete review failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(out); err != nil {
+		klog.Errorf("writing admission response: %v", err)
+	}
+}
+
+// validatePVCDelete denies deletion of a mounted managed claim. Requests the
+// webhook cannot check (no client configured) are allowed; deletion
+// protection is best effort, not a security boundary.
+func (s *Server) validatePVCDelete(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	// On DELETE the object under review arrives in OldObject.
+	raw := req.OldObject.Raw
+	if len(raw) == 0 {
+		raw = req.Object.Raw
+	}
+	var pvc struct {
+		metav1.ObjectMeta `json:"metadata"`
+	}
+	if err := json.Unmarshal(raw, &pvc); err != nil {
+		return errorResponse(denyf(metav1.StatusReasonBadRequest, 400, "decoding claim: %v", err))
+	}
+	if pvc.Labels["created-by"] != "pvc-webhook" {
+		return allowResponse()
+	}
+	if pvc.Annotations[annotationForceDelete] == "true" {
+		klog.InfoS("allowing forced deletion of managed claim",
+			"uid", req.UID, "namespace", req.Namespace, "name", req.Name)
+		return allowResponse()
+	}
+	if s.client == nil {
+		klog.V(1).InfoS("no client configured, allowing managed claim deletion unchecked",
+			"uid", req.UID, "namespace", req.Namespace, "name", req.Name)
+		return allowResponse()
+	}
+
+	pods, err := s.client.CoreV1().Pods(req.Namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("listing pods for claim %s/%s: %v", req.Namespace, req.Name, err)
+		return allowResponse()
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == req.Name {
+				return errorResponse(denyf(metav1.StatusReasonForbidden, 403,
+					"managed claim is still mounted by pod %q; annotate the claim with %s=true to delete anyway",
+					pod.Name, annotationForceDelete))
+			}
+		}
+	}
+	return allowResponse()
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// Mutating workload objects directly (instead of only the pods they generate)
+// makes the conversion visible in the workload spec itself and in GitOps
+// diffs. Each supported kind maps to the JSON Pointer prefix of its pod
+// template.
+
+// templatePathPrefix returns the JSON Pointer prefix of the pod template for
+// a supported workload kind, and whether the kind is supported.
+func templatePathPrefix(kind metav1.GroupVersionKind) (string, bool) {
+	switch kind.Group {
+	case "apps":
+		switch kind.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet":
+			return "/spec/template", true
+		}
+	case "batch":
+		switch kind.Kind {
+		case "Job":
+			return "/spec/template", true
+		case "CronJob":
+			return "/spec/jobTemplate/spec/template", true
+		}
+	}
+	return "", false
+}
+
+// mutateWorkload converts emptyDir volumes in a workload's pod template. The
+// resulting patch operations target the template, so the conversion lands in
+// the workload object and every pod it generates.
+func (s *Server) mutateWorkload(req *admissionv1.AdmissionRequest, prefix string) *admissionv1.AdmissionResponse {
+	tpl, err := decodeTemplate(req.Object.Raw, prefix)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	// Reuse the pod mutation logic by treating the template as a pod named
+	// after the workload, so all generated pods share deterministic claims.
+	var meta metav1.ObjectMeta
+	if err := json.Unmarshal(req.Object.Raw, &struct {
+		Metadata *metav1.ObjectMeta `json:"metadata"`
+	}{&meta}); err != nil {
+		return errorResponse(fmt.Errorf("decoding workload metadata: %v", err))
+	}
+	pod := getPod()
+	defer putPod(pod)
+	pod.ObjectMeta = tpl.ObjectMeta
+	pod.Spec = tpl.Spec
+	pod.Name = meta.Name
+
+	if s.cfg.StrictValidation {
+		if err := s.validatePod(pod); err != nil {
+			return errorResponse(err)
+		}
+	}
+
+	canaryKey := string(meta.UID)
+	if canaryKey == "" {
+		canaryKey = req.Namespace + "/" + meta.Name
+	}
+	if !s.canarySelected(req.Namespace, canaryKey) {
+		return allowResponse()
+	}
+
+	patch, converted := s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+	defer func() { putPatch(patch) }()
+	if len(patch) == 0 {
+		return allowResponse()
+	}
+	if s.cfg.ShadowMode {
+		s.logShadowDecision(req.UID, req.Kind.Kind, req.Namespace, meta.Name, converted)
+		return allowResponse()
+	}
+	var capWarnings []string
+	if max := s.cfg.MaxVolumesPerPod; max > 0 && len(converted) > max {
+		if s.cfg.RejectExcessVolumes {
+			return errorResponse(denyf(ReasonTooManyVolumes, 403, "pod template requests %d converted volumes, the limit is %d", len(converted), max))
+		}
+		capWarnings = capConvertedVolumes(pod, converted, max)
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	if s.applyCELRules(pod, req.Object.Raw, converted) {
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	if adjusted, err := s.applyPolicy(req.Namespace, req.Object.Raw, pod, converted); err != nil {
+		return errorResponse(err)
+	} else if adjusted {
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	clamped, limitWarnings := s.clampConvertedSizes(req.Namespace, pod, converted)
+	if clamped {
+		putPatch(patch)
+		patch, converted = s.buildPatch(pod, s.namespaceStorageClass(req.Namespace))
+		if len(patch) == 0 {
+			return allowResponse()
+		}
+	}
+	if err := s.enforceEncryption(req.Namespace, pod, converted); err != nil {
+		return errorResponse(err)
+	}
+	traceAnnotations(patch, req.UID)
+	for i := range patch {
+		patch[i].Path = prefix + patch[i].Path
+	}
+	klog.InfoS("mutating workload template",
+		"uid", req.UID, "kind", req.Kind.Kind, "namespace", req.Namespace, "name", meta.Name, "operations", len(patch))
+	warnings := append(s.conversionWarnings(pod, converted), capWarnings...)
+	return jsonPatchResponse(patch, append(warnings, limitWarnings...))
+}
+
+// decodeTemplate extracts the pod template at the given JSON Pointer prefix.
+// Templates decode into the same lean admissionPod shape as pods, skipping the
+// container definitions the mutation logic never reads.
+func decodeTemplate(raw []byte, prefix string) (*admissionPod, error) {
+	switch prefix {
+	case "/spec/template":
+		obj := struct {
+			Spec struct {
+				Template admissionPod `json:"template"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, fmt.Errorf("decoding workload: %v", err)
+		}
+		return &obj.Spec.Template, nil
+	case "/spec/jobTemplate/spec/template":
+		obj := struct {
+			Spec struct {
+				JobTemplate struct {
+					Spec struct {
+						Template admissionPod `json:"template"`
+					} `json:"spec"`
+				} `json:"jobTemplate"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, fmt.Errorf("decoding cronjob: %v", err)
+		}
+		return &obj.Spec.JobTemplate.Spec.Template, nil
+	}
+	return nil, fmt.Errorf("unsupported template path %q", prefix)
+}
@@ -0,0 +1,151 @@
+// Package webhooktest helps downstream users test their policies against the
+// webhook contract without a cluster: builders assemble AdmissionReview
+// requests for pods with emptyDir volumes, and Run serves them through the
+// real HTTP handler and hands back the decoded patch.
+package webhooktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	jsonpatch "gomodules.xyz/jsonpatch/v2"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/webhook"
+)
+
+// PodBuilder assembles a pod for admission tests. The zero builder is not
+// usable; start with NewPod.
+type PodBuilder struct {
+	pod corev1.Pod
+}
+
+// NewPod returns a builder for a minimal valid pod with one pause container
+// and no volumes.
+func NewPod(namespace, name string) *PodBuilder {
+	return &PodBuilder{pod: corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			UID:       types.UID("test-" + namespace + "-" + name),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Image: "registry.k8s.io/pause:3.9",
+			}},
+		},
+	}}
+}
+
+// WithEmptyDir adds a named emptyDir volume, mounted into the first container.
+func (b *PodBuilder) WithEmptyDir(name string) *PodBuilder {
+	b.pod.Spec.Volumes = append(b.pod.Spec.Volumes, corev1.Volume{
+		Name:         name,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	b.pod.Spec.Containers[0].VolumeMounts = append(b.pod.Spec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{Name: name, MountPath: "/" + name})
+	return b
+}
+
+// WithEmptyDirs adds n emptyDir volumes named scratch-0 through scratch-n-1.
+func (b *PodBuilder) WithEmptyDirs(n int) *PodBuilder {
+	for i := 0; i < n; i++ {
+		b.WithEmptyDir(fmt.Sprintf("scratch-%d", i))
+	}
+	return b
+}
+
+// WithAnnotation sets an arbitrary pod annotation.
+func (b *PodBuilder) WithAnnotation(key, value string) *PodBuilder {
+	if b.pod.Annotations == nil {
+		b.pod.Annotations = map[string]string{}
+	}
+	b.pod.Annotations[key] = value
+	return b
+}
+
+// WithVolumeParam sets a per-volume parameter annotation using the canonical
+// schema, e.g. WithVolumeParam("cache", annotations.Size, "10Gi").
+func (b *PodBuilder) WithVolumeParam(volume, param, value string) *PodBuilder {
+	return b.WithAnnotation(annotations.Key(volume, param), value)
+}
+
+// Pod returns the built pod.
+func (b *PodBuilder) Pod() *corev1.Pod {
+	return b.pod.DeepCopy()
+}
+
+// Review wraps the pod in an admission.k8s.io/v1 AdmissionReview for a pod
+// CREATE, the shape the API server sends the webhook.
+func Review(pod *corev1.Pod) *admissionv1.AdmissionReview {
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		// A corev1.Pod always marshals; failure means the test handed in
+		// something unserializable and deserves the panic.
+		panic(fmt.Sprintf("webhooktest: encoding pod: %v", err))
+	}
+	return &admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:       types.UID("review-" + pod.Namespace + "-" + pod.Name),
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "pods"},
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+// NewServer starts an httptest server running the webhook with the given
+// config. The caller owns the server and must Close it.
+func NewServer(cfg webhook.Config) *httptest.Server {
+	return httptest.NewServer(webhook.NewServer(cfg).Handler())
+}
+
+// Run serves the review through the webhook's /mutate handler and returns the
+// admission response together with the decoded patch operations. A nil patch
+// slice means the pod was admitted unmodified.
+func Run(cfg webhook.Config, review *admissionv1.AdmissionReview) (*admissionv1.AdmissionResponse, []jsonpatch.Operation, error) {
+	srv := NewServer(cfg)
+	defer srv.Close()
+
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding review: %v", err)
+	}
+	resp, err := http.Post(srv.URL+"/mutate", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	var out admissionv1.AdmissionReview
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, nil, fmt.Errorf("decoding review response: %v", err)
+	}
+	if out.Response == nil {
+		return nil, nil, fmt.Errorf("review response has no response object")
+	}
+	var ops []jsonpatch.Operation
+	if len(out.Response.Patch) > 0 {
+		if err := json.Unmarshal(out.Response.Patch, &ops); err != nil {
+			return nil, nil, fmt.Errorf("decoding patch: %v", err)
+		}
+	}
+	return out.Response, ops, nil
+}
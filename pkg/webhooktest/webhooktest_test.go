@@ -0,0 +1,40 @@
+package webhooktest
+
+import (
+	"testing"
+
+	"github.com/rkitindi-kr/pvc-webhook/pkg/annotations"
+	"github.com/rkitindi-kr/pvc-webhook/pkg/webhook"
+)
+
+// TestRunConvertsEmptyDirs doubles as a usage example: build a pod, run it
+// through the webhook contract, and inspect the decoded patch.
+func TestRunConvertsEmptyDirs(t *testing.T) {
+	pod := NewPod("default", "web-0").
+		WithEmptyDirs(2).
+		WithVolumeParam("scratch-0", annotations.Size, "10Gi").
+		Pod()
+
+	resp, ops, err := Run(webhook.Config{DefaultSize: "1Gi", ConversionPercentage: 100}, Review(pod))
+	if err != nil {
+		t.Fatalf("running review: %v", err)
+	}
+	if !resp.Allowed {
+		t.Fatalf("pod was denied: %v", resp.Result)
+	}
+	if len(ops) == 0 {
+		t.Fatal("expected a patch converting the emptyDir volumes, got none")
+	}
+
+	replaced := map[string]bool{}
+	for _, op := range ops {
+		if op.Operation == "replace" {
+			replaced[op.Path] = true
+		}
+	}
+	for _, path := range []string{"/spec/volumes/0", "/spec/volumes/1"} {
+		if !replaced[path] {
+			t.Errorf("expected a replace op for %s, got %v", path, ops)
+		}
+	}
+}